@@ -0,0 +1,188 @@
+package juno_go
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bytesonus/juno-go/protocol"
+)
+
+const (
+	hookReplyFunction = "__hook_reply"
+	ackIdMetaKey      = "juno-ack-id"
+	replyToMetaKey    = "juno-reply-to"
+	replyTimeout      = 10 * time.Second
+)
+
+// DefaultAcknowledgementWindow is how long TriggerHookAcknowledged collects
+// receipts when its context has no deadline.
+var DefaultAcknowledgementWindow = 2 * time.Second
+
+// DeliveryReport lists the receipts sent back for an acknowledged hook.
+// Receipts are opt-in: only modules built on this library send them, so
+// listeners using other juno clients receive the hook without showing up in
+// the report, which is therefore partial. Receivers find the trigger through
+// the hook's metadata, which juno doesn't forward, so against juno reports
+// are empty.
+type DeliveryReport struct {
+	Hook     string
+	Receipts []DeliveryReceipt
+}
+
+type DeliveryReceipt struct {
+	Module string `json:"module"`
+	// Listeners is how many listeners the module ran for the hook.
+	Listeners int               `json:"listeners"`
+	Failures  []ListenerFailure `json:"failures,omitempty"`
+	// Results holds the answers of query handlers, see Gather.
+	Results []interface{} `json:"results,omitempty"`
+}
+
+// ListenerFailure is an error returned by a hook listener. The listener is
+// identified by the hook or pattern it was registered for and its id, the
+// one Subscription.Id returns in the failing module.
+type ListenerFailure struct {
+	Pattern  string `json:"pattern"`
+	Listener uint64 `json:"listener"`
+	Error    string `json:"error"`
+}
+
+// Failed reports whether any listener returned an error.
+func (report DeliveryReport) Failed() bool {
+	for _, receipt := range report.Receipts {
+		if len(receipt.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type AckListType struct {
+	sync.Mutex
+	m map[string]*ackCollector
+}
+
+type ackCollector struct {
+	receipts []DeliveryReceipt
+	// expected is how many receipts complete the report, or 0 to collect
+	// until the window closes. complete is closed once they arrived.
+	expected int
+	complete chan struct{}
+}
+
+type hookReply struct {
+	Id string `json:"id"`
+	DeliveryReceipt
+}
+
+// ExpectReceipts makes TriggerHookAcknowledged return as soon as count
+// receipts arrived, instead of waiting for the window to close.
+func ExpectReceipts(count int) CallOption {
+	return func(options *callOptions) {
+		options.receipts = count
+	}
+}
+
+// TriggerHookAcknowledged triggers hook and asks every receiving module to
+// report back once its listeners ran. Receipts are collected until ctx is done,
+// or for DefaultAcknowledgementWindow when ctx has no deadline. Since the
+// gateway doesn't say how many modules listen, the window runs out unless
+// ExpectReceipts says how many receipts to wait for. Only modules opting in
+// send receipts, see DeliveryReport.
+func (module *JunoModule) TriggerHookAcknowledged(ctx context.Context, hook string, data interface{}, opts ...CallOption) (DeliveryReport, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultAcknowledgementWindow)
+		defer cancel()
+	}
+
+	id := protocol.GenerateRequestId(module.moduleId)
+	collector := &ackCollector{
+		expected: newCallOptions(opts).receipts,
+		complete: make(chan struct{}),
+	}
+	module.acks.Lock()
+	module.acks.m[id] = collector
+	module.acks.Unlock()
+	defer func() {
+		module.acks.Lock()
+		delete(module.acks.m, id)
+		module.acks.Unlock()
+	}()
+
+	opts = append(opts, WithMeta(ackIdMetaKey, id), WithMeta(replyToMetaKey, module.moduleId))
+	channel, err := module.TriggerHookContext(ctx, hook, data, opts...)
+	if err != nil {
+		return DeliveryReport{}, err
+	}
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			return DeliveryReport{}, err
+		}
+	case <-ctx.Done():
+		return DeliveryReport{}, ctx.Err()
+	}
+
+	select {
+	case <-collector.complete:
+	case <-ctx.Done():
+	}
+	module.acks.Lock()
+	report := DeliveryReport{
+		Hook:     module.moduleId + "." + hook,
+		Receipts: append([]DeliveryReceipt{}, collector.receipts...),
+	}
+	module.acks.Unlock()
+	if ctx.Err() == context.Canceled {
+		return report, ctx.Err()
+	}
+	return report, nil
+}
+
+func (module *JunoModule) serveHookReply(args map[string]interface{}) interface{} {
+	var reply hookReply
+	if err := convert(args, &reply); err != nil {
+		return failure(err)
+	}
+	module.acks.Lock()
+	defer module.acks.Unlock()
+	collector := module.acks.m[reply.Id]
+	if collector == nil {
+		// The window already closed.
+		return false
+	}
+	collector.receipts = append(collector.receipts, reply.DeliveryReceipt)
+	if len(collector.receipts) == collector.expected {
+		close(collector.complete)
+	}
+	return true
+}
+
+// replyToHook sends the receipt for an acknowledged hook delivery, if the
+// trigger asked for one.
+func (module *JunoModule) replyToHook(meta map[string]string, listeners int, failures []ListenerFailure, results []interface{}) {
+	id, replyTo := meta[ackIdMetaKey], meta[replyToMetaKey]
+	if id == "" || replyTo == "" {
+		return
+	}
+	reply := hookReply{
+		Id: id,
+		DeliveryReceipt: DeliveryReceipt{
+			Module:    module.moduleId,
+			Listeners: listeners,
+			Failures:  failures,
+			Results:   results,
+		},
+	}
+	var args map[string]interface{}
+	if err := convert(reply, &args); err != nil {
+		module.logger.Error("failed to encode hook receipt", "error", err)
+		return
+	}
+	_, err := module.CallFunction(replyTo+"."+hookReplyFunction, args, WithTimeout(replyTimeout))
+	if err != nil {
+		module.logger.Warn("failed to send hook receipt", "to", replyTo, "error", err)
+	}
+}
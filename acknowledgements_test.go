@@ -0,0 +1,40 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTriggerHookAcknowledged(t *testing.T) {
+	address := startGateway(t)
+	trigger := startModule(t, address, "trigger")
+	listener := startModule(t, address, "listener")
+	channel, subscription, err := listener.RegisterHookContext("trigger.paid", func(ctx context.Context, data interface{}) error {
+		return errors.New("declined")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	await(t, channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	started := time.Now()
+	report, err := trigger.TriggerHookAcknowledged(ctx, "paid", nil, ExpectReceipts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("took %v, want it to return once the receipt arrived", elapsed)
+	}
+	if len(report.Receipts) != 1 || report.Receipts[0].Module != "listener" {
+		t.Fatalf("got receipts %+v", report.Receipts)
+	}
+	failures := report.Receipts[0].Failures
+	want := ListenerFailure{Pattern: "trigger.paid", Listener: subscription.Id(), Error: "declined"}
+	if len(failures) != 1 || failures[0] != want {
+		t.Fatalf("got failures %+v, want %+v", failures, want)
+	}
+}
@@ -0,0 +1,286 @@
+package bench
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+)
+
+// Config describes a benchmark run. Every module declares an echo function
+// that the next module calls, and triggers a tick hook that the next module
+// listens to, so traffic flows through the gateway in a ring.
+type Config struct {
+	// Dial creates a module connected to the gateway under test.
+	Dial     func() (*juno.JunoModule, error)
+	Modules  int
+	Duration time.Duration
+	// CallRate and HookRate are totals per second across all modules. A
+	// rate of 0 calls as fast as Concurrency allows and disables hooks
+	// respectively.
+	CallRate    int
+	HookRate    int
+	Concurrency int
+	PayloadSize int
+	Timeout     time.Duration
+}
+
+type Percentiles struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+type Report struct {
+	Duration       time.Duration
+	Calls          uint64
+	CallErrors     uint64
+	HooksTriggered uint64
+	HooksReceived  uint64
+	CallsPerSecond float64
+	HooksPerSecond float64
+	Latency        Percentiles
+}
+
+type recorder struct {
+	sync.Mutex
+	latencies      []time.Duration
+	calls          uint64
+	callErrors     uint64
+	hooksTriggered uint64
+	hooksReceived  uint64
+}
+
+func (recorder *recorder) observe(latency time.Duration) {
+	recorder.Lock()
+	recorder.latencies = append(recorder.latencies, latency)
+	recorder.Unlock()
+}
+
+func Run(ctx context.Context, config Config) (Report, error) {
+	if config.Dial == nil {
+		return Report{}, errors.New("bench: Dial is required")
+	}
+	if config.Modules < 1 {
+		config.Modules = 1
+	}
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	ids := moduleIds(config.Modules)
+	modules, err := setup(config, ids)
+	defer func() {
+		for _, module := range modules {
+			_ = module.Close()
+		}
+	}()
+	if err != nil {
+		return Report{}, err
+	}
+
+	stats := &recorder{}
+	for i := range modules {
+		listener := modules[(i+1)%len(modules)]
+		channel, _, err := listener.RegisterHook(ids[i]+".tick", func(interface{}) {
+			atomic.AddUint64(&stats.hooksReceived, 1)
+		})
+		if err != nil {
+			return Report{}, err
+		}
+		if err := await(channel, config.Timeout); err != nil {
+			return Report{}, fmt.Errorf("bench: registering %s.tick: %v", ids[i], err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	payload := strings.Repeat("x", config.PayloadSize)
+	startedAt := time.Now()
+	var workers sync.WaitGroup
+	for i, module := range modules {
+		target := ids[(i+1)%len(modules)] + ".echo"
+		for worker := 0; worker < config.Concurrency; worker++ {
+			workers.Add(1)
+			go func(module *juno.JunoModule) {
+				defer workers.Done()
+				call(ctx, module, target, payload, pace(config.CallRate, len(modules)*config.Concurrency), config.Timeout, stats)
+			}(module)
+		}
+		if config.HookRate > 0 {
+			workers.Add(1)
+			go func(module *juno.JunoModule) {
+				defer workers.Done()
+				trigger(ctx, module, payload, pace(config.HookRate, len(modules)), stats)
+			}(module)
+		}
+	}
+	workers.Wait()
+	elapsed := time.Since(startedAt)
+
+	report := Report{
+		Duration:       elapsed,
+		Calls:          atomic.LoadUint64(&stats.calls),
+		CallErrors:     atomic.LoadUint64(&stats.callErrors),
+		HooksTriggered: atomic.LoadUint64(&stats.hooksTriggered),
+		HooksReceived:  atomic.LoadUint64(&stats.hooksReceived),
+		Latency:        percentiles(stats.latencies),
+	}
+	report.CallsPerSecond = float64(report.Calls) / elapsed.Seconds()
+	report.HooksPerSecond = float64(report.HooksTriggered) / elapsed.Seconds()
+	return report, nil
+}
+
+func setup(config Config, ids []string) ([]*juno.JunoModule, error) {
+	modules := []*juno.JunoModule{}
+	for i := 0; i < config.Modules; i++ {
+		module, err := config.Dial()
+		if err != nil {
+			return modules, err
+		}
+		modules = append(modules, module)
+
+		channel, err := module.Initialize(ids[i], "1.0.0", nil)
+		if err != nil {
+			return modules, err
+		}
+		if err := await(channel, config.Timeout); err != nil {
+			return modules, fmt.Errorf("bench: registering %s: %v", ids[i], err)
+		}
+
+		channel, err = module.DeclareFunction("echo", func(args map[string]interface{}) interface{} {
+			return args["payload"]
+		})
+		if err != nil {
+			return modules, err
+		}
+		if err := await(channel, config.Timeout); err != nil {
+			return modules, fmt.Errorf("bench: declaring %s.echo: %v", ids[i], err)
+		}
+	}
+	return modules, nil
+}
+
+func call(ctx context.Context, module *juno.JunoModule, target, payload string, interval time.Duration, timeout time.Duration, stats *recorder) {
+	ticker := newTicker(interval)
+	defer ticker.stop()
+	for ticker.wait(ctx) {
+		sentAt := time.Now()
+		channel, err := module.CallFunction(target, map[string]interface{}{"payload": payload}, juno.WithTimeout(timeout))
+		if err == nil {
+			if _, failed := (<-channel).(error); failed {
+				err = errors.New("call failed")
+			}
+		}
+		atomic.AddUint64(&stats.calls, 1)
+		if err != nil {
+			atomic.AddUint64(&stats.callErrors, 1)
+			continue
+		}
+		stats.observe(time.Since(sentAt))
+	}
+}
+
+func trigger(ctx context.Context, module *juno.JunoModule, payload string, interval time.Duration, stats *recorder) {
+	ticker := newTicker(interval)
+	defer ticker.stop()
+	for ticker.wait(ctx) {
+		channel, err := module.TriggerHook("tick", payload)
+		if err != nil {
+			continue
+		}
+		<-channel
+		atomic.AddUint64(&stats.hooksTriggered, 1)
+	}
+}
+
+// moduleIds names the modules of a run. A random suffix keeps runs against
+// the same gateway from colliding.
+func moduleIds(count int) []string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("bench-%x-%d", suffix, i)
+	}
+	return ids
+}
+
+func await(channel chan interface{}, timeout time.Duration) error {
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			return err
+		}
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timed out")
+	}
+}
+
+// pace returns the interval at which each of workers has to act for all of
+// them together to reach rate per second, or 0 for no limit.
+func pace(rate, workers int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(int64(time.Second) * int64(workers) / int64(rate))
+}
+
+type ticker struct {
+	ticker *time.Ticker
+}
+
+func newTicker(interval time.Duration) *ticker {
+	if interval <= 0 {
+		return &ticker{}
+	}
+	return &ticker{ticker: time.NewTicker(interval)}
+}
+
+func (ticker *ticker) wait(ctx context.Context) bool {
+	if ticker.ticker == nil {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-ticker.ticker.C:
+		return ctx.Err() == nil
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (ticker *ticker) stop() {
+	if ticker.ticker != nil {
+		ticker.ticker.Stop()
+	}
+}
+
+func percentiles(latencies []time.Duration) Percentiles {
+	if len(latencies) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(quantile float64) time.Duration {
+		return sorted[int(quantile*float64(len(sorted)-1))]
+	}
+	return Percentiles{
+		P50: at(0.50),
+		P90: at(0.90),
+		P99: at(0.99),
+		Max: sorted[len(sorted)-1],
+	}
+}
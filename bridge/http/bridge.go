@@ -0,0 +1,217 @@
+// Package http exposes a module's view of juno over HTTP, so that services
+// without a juno client and browsers can call functions, trigger hooks and
+// follow hook events.
+//
+//	POST /call/{module}/{function}  JSON arguments in, JSON result out
+//	POST /hooks/{hook}              JSON hook data in
+//	GET  /events?hook={module.hook} server-sent events, one per hook delivery
+//
+// Headers starting with Juno-Meta- are forwarded as call metadata.
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/utils/error_codes"
+)
+
+const metaHeaderPrefix = "Juno-Meta-"
+
+type Bridge struct {
+	module *juno.JunoModule
+	// Timeout bounds calls and triggers. Clients can shorten it with a
+	// timeout query parameter such as ?timeout=2s.
+	Timeout     time.Duration
+	MaxBodySize int64
+	// KeepAlive is the interval of comments sent on idle event streams.
+	KeepAlive time.Duration
+	events    *broker
+}
+
+// New creates a bridge that acts through module, which must be initialized.
+func New(module *juno.JunoModule) *Bridge {
+	return &Bridge{
+		module:      module,
+		Timeout:     30 * time.Second,
+		MaxBodySize: 1 << 20,
+		KeepAlive:   15 * time.Second,
+		events:      newBroker(module),
+	}
+}
+
+func (bridge *Bridge) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "call":
+		if r.Method != nethttp.MethodPost {
+			methodNotAllowed(w, nethttp.MethodPost)
+			return
+		}
+		bridge.call(w, r, parts[1]+"."+parts[2])
+	case len(parts) == 2 && parts[0] == "hooks":
+		if r.Method != nethttp.MethodPost {
+			methodNotAllowed(w, nethttp.MethodPost)
+			return
+		}
+		bridge.trigger(w, r, parts[1])
+	case path == "events":
+		if r.Method != nethttp.MethodGet {
+			methodNotAllowed(w, nethttp.MethodGet)
+			return
+		}
+		bridge.stream(w, r)
+	default:
+		writeError(w, nethttp.StatusNotFound, "not found")
+	}
+}
+
+func (bridge *Bridge) call(w nethttp.ResponseWriter, r *nethttp.Request, function string) {
+	var args map[string]interface{}
+	if err := bridge.decodeBody(r, &args); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel, err := bridge.context(r)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	defer cancel()
+
+	channel, err := bridge.module.CallFunctionContext(ctx, function, args, metaOptions(r)...)
+	if err != nil {
+		writeError(w, nethttp.StatusBadGateway, err.Error())
+		return
+	}
+	result, err := await(ctx, channel)
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	writeJson(w, nethttp.StatusOK, result)
+}
+
+func (bridge *Bridge) trigger(w nethttp.ResponseWriter, r *nethttp.Request, hook string) {
+	var data interface{}
+	if err := bridge.decodeBody(r, &data); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel, err := bridge.context(r)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	defer cancel()
+
+	channel, err := bridge.module.TriggerHookContext(ctx, hook, data, metaOptions(r)...)
+	if err != nil {
+		writeError(w, nethttp.StatusBadGateway, err.Error())
+		return
+	}
+	if _, err := await(ctx, channel); err != nil {
+		writeFailure(w, err)
+		return
+	}
+	w.WriteHeader(nethttp.StatusNoContent)
+}
+
+func (bridge *Bridge) decodeBody(r *nethttp.Request, out interface{}) error {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, bridge.MaxBodySize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > bridge.MaxBodySize {
+		return fmt.Errorf("body is larger than %d bytes", bridge.MaxBodySize)
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+func (bridge *Bridge) context(r *nethttp.Request) (context.Context, context.CancelFunc, error) {
+	timeout := bridge.Timeout
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		requested, err := time.ParseDuration(value)
+		if err != nil || requested <= 0 {
+			return nil, nil, fmt.Errorf("invalid timeout %q", value)
+		}
+		if requested < timeout {
+			timeout = requested
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return ctx, cancel, nil
+}
+
+func metaOptions(r *nethttp.Request) []juno.CallOption {
+	options := []juno.CallOption{}
+	for name, values := range r.Header {
+		if strings.HasPrefix(name, metaHeaderPrefix) && len(values) > 0 {
+			key := strings.ToLower(strings.TrimPrefix(name, metaHeaderPrefix))
+			options = append(options, juno.WithMeta(key, values[0]))
+		}
+	}
+	return options
+}
+
+func await(ctx context.Context, channel chan interface{}) (interface{}, error) {
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+		return result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func writeFailure(w nethttp.ResponseWriter, err error) {
+	var gatewayError *juno.GatewayError
+	var functionError *juno.FunctionError
+	switch {
+	case errors.As(err, &functionError):
+		writeError(w, nethttp.StatusUnprocessableEntity, functionError.Message)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, nethttp.StatusGatewayTimeout, err.Error())
+	case errors.Is(err, context.Canceled):
+		writeError(w, nethttp.StatusServiceUnavailable, err.Error())
+	case errors.As(err, &gatewayError) &&
+		(gatewayError.Code == error_codes.UnknownModule || gatewayError.Code == error_codes.UnknownFunction):
+		writeError(w, nethttp.StatusNotFound, err.Error())
+	default:
+		writeError(w, nethttp.StatusBadGateway, err.Error())
+	}
+}
+
+func methodNotAllowed(w nethttp.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, nethttp.StatusMethodNotAllowed, "method not allowed")
+}
+
+func writeError(w nethttp.ResponseWriter, status int, message string) {
+	writeJson(w, status, map[string]string{"error": message})
+}
+
+func writeJson(w nethttp.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
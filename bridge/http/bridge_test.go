@@ -0,0 +1,190 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/gateway"
+	"github.com/bytesonus/juno-go/utils/error_codes"
+)
+
+// startBridge serves a "server" module through a gateway, and bridges through
+// a second module. It returns the server module and the bridge.
+func startBridge(t *testing.T) (*juno.JunoModule, *Bridge) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gateway.New().Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	modules := []*juno.JunoModule{}
+	for _, moduleId := range []string{"server", "bridge"} {
+		module := juno.Default(listener.Addr().String())
+		channel, err := module.Initialize(moduleId, "1.0.0", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		await(context.Background(), channel)
+		t.Cleanup(func() { module.Close() })
+		modules = append(modules, &module)
+	}
+
+	declare := func(name string, function interface{}) {
+		channel, err := modules[0].DeclareTypedFunction(name, function)
+		if err != nil {
+			t.Fatal(err)
+		}
+		await(context.Background(), channel)
+	}
+	declare("echo", func(ctx context.Context, args struct {
+		Item string `json:"item"`
+	}) (interface{}, error) {
+		return args, nil
+	})
+	declare("fail", func(ctx context.Context, args struct{}) error {
+		return errors.New("out of stock")
+	})
+	declare("slow", func(ctx context.Context, args struct{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	return modules[0], New(modules[1])
+}
+
+func TestRouting(t *testing.T) {
+	_, bridge := startBridge(t)
+	tests := []struct {
+		method, target, body string
+		status               int
+		// answer is the expected body, or empty to skip the check.
+		answer string
+	}{
+		{"POST", "/call/server/echo", `{"item":"book"}`, 200, `{"item":"book"}`},
+		{"POST", "/call/server/fail", ``, 422, `{"error":"out of stock"}`},
+		{"POST", "/call/server/missing", ``, 404, ``},
+		{"POST", "/call/nobody/echo", ``, 404, ``},
+		{"POST", "/call/server/slow?timeout=50ms", ``, 504, ``},
+		{"POST", "/call/server/echo?timeout=soon", ``, 400, `{"error":"invalid timeout \"soon\""}`},
+		{"POST", "/call/server/echo", `{"item":`, 400, ``},
+		{"GET", "/call/server/echo", ``, 405, `{"error":"method not allowed"}`},
+		{"POST", "/hooks/created", `{"id":"1"}`, 204, ``},
+		{"POST", "/events", ``, 405, ``},
+		{"GET", "/events", ``, 400, `{"error":"at least one hook query parameter is required"}`},
+		{"GET", "/call/server", ``, 404, `{"error":"not found"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.target, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			bridge.ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, strings.NewReader(test.body)))
+			if recorder.Code != test.status {
+				t.Errorf("got status %d, want %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			if test.answer != "" && strings.TrimSpace(recorder.Body.String()) != test.answer {
+				t.Errorf("got %s, want %s", recorder.Body, test.answer)
+			}
+			if test.status == 405 && recorder.Header().Get("Allow") == "" {
+				t.Error("405 without an Allow header")
+			}
+		})
+	}
+}
+
+func TestWriteFailure(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{&juno.FunctionError{Message: "out of stock"}, 422},
+		{fmt.Errorf("calling: %w", context.DeadlineExceeded), 504},
+		{context.Canceled, 503},
+		{&juno.GatewayError{Code: error_codes.UnknownModule}, 404},
+		{&juno.GatewayError{Code: error_codes.UnknownFunction}, 404},
+		{&juno.GatewayError{Code: error_codes.InvalidRequestId}, 502},
+		{errors.New("connection lost"), 502},
+	} {
+		recorder := httptest.NewRecorder()
+		writeFailure(recorder, test.err)
+		if recorder.Code != test.status {
+			t.Errorf("%v: got status %d, want %d", test.err, recorder.Code, test.status)
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server, bridge := startBridge(t)
+	bridge.KeepAlive = 50 * time.Millisecond
+	httpServer := httptest.NewServer(bridge)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, err := nethttp.NewRequestWithContext(ctx, "GET", httpServer.URL+"/events?hook=server.created", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := nethttp.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("got content type %q", response.Header.Get("Content-Type"))
+	}
+
+	// The headers are sent once the hook is registered.
+	channel, err := server.TriggerHook("created", map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	await(ctx, channel)
+
+	reader := bufio.NewReader(response.Body)
+	frames := []string{}
+	for len(frames) < 2 {
+		frame := ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading the stream: %v", err)
+			}
+			if line == "\n" {
+				break
+			}
+			frame += line
+		}
+		frames = append(frames, frame)
+	}
+	if frames[0] != "event: server.created\ndata: {\"id\":\"1\"}\n" {
+		t.Errorf("got event frame %q", frames[0])
+	}
+	if frames[1] != ": keep-alive\n" {
+		t.Errorf("got frame %q, want a keep-alive", frames[1])
+	}
+
+	// Closing the stream stops listening to the hook.
+	response.Body.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bridge.events.registration.Lock()
+		registered := len(bridge.events.registered)
+		bridge.events.registration.Unlock()
+		if registered == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("hook still registered after the stream closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+)
+
+// subscriberBuffer is how many events a slow event stream may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 64
+
+type event struct {
+	hook string
+	data []byte
+}
+
+// broker registers each hook with the module once and fans its deliveries out
+// to every event stream following it.
+type broker struct {
+	sync.Mutex
+	module      *juno.JunoModule
+	subscribers map[string]map[chan event]bool
+	// registration is held while hooks are registered or unregistered, so
+	// that the round trip to the gateway doesn't hold up publishing.
+	registration sync.Mutex
+	registered   map[string]*juno.Subscription
+}
+
+func newBroker(module *juno.JunoModule) *broker {
+	return &broker{
+		module:      module,
+		subscribers: make(map[string]map[chan event]bool),
+		registered:  make(map[string]*juno.Subscription),
+	}
+}
+
+func (broker *broker) subscribe(ctx context.Context, hooks []string) (chan event, error) {
+	channel := make(chan event, subscriberBuffer)
+	broker.registration.Lock()
+	defer broker.registration.Unlock()
+	for i, hook := range hooks {
+		broker.Lock()
+		if broker.subscribers[hook] == nil {
+			broker.subscribers[hook] = make(map[chan event]bool)
+		}
+		broker.subscribers[hook][channel] = true
+		broker.Unlock()
+
+		if err := broker.register(ctx, hook); err != nil {
+			broker.remove(channel, hooks[:i+1])
+			return nil, err
+		}
+	}
+	return channel, nil
+}
+
+func (broker *broker) unsubscribe(channel chan event, hooks []string) {
+	broker.registration.Lock()
+	defer broker.registration.Unlock()
+	broker.remove(channel, hooks)
+}
+
+// remove stops channel from receiving hooks, and stops listening to the hooks
+// nobody follows anymore. The caller holds the registration lock.
+func (broker *broker) remove(channel chan event, hooks []string) {
+	unused := []*juno.Subscription{}
+	broker.Lock()
+	for _, hook := range hooks {
+		delete(broker.subscribers[hook], channel)
+		if len(broker.subscribers[hook]) > 0 {
+			continue
+		}
+		delete(broker.subscribers, hook)
+		if subscription := broker.registered[hook]; subscription != nil {
+			delete(broker.registered, hook)
+			unused = append(unused, subscription)
+		}
+	}
+	broker.Unlock()
+
+	// Unsubscribing goes to the gateway, so it mustn't hold up publishing.
+	for _, subscription := range unused {
+		_, _ = subscription.Unsubscribe()
+	}
+}
+
+// register listens to hook unless that's already the case. The caller holds
+// the registration lock.
+func (broker *broker) register(ctx context.Context, hook string) error {
+	if broker.registered[hook] != nil {
+		return nil
+	}
+
+	channel, subscription, err := broker.module.RegisterHookPattern(hook, func(ctx context.Context, delivered juno.HookEvent) error {
+		broker.publish(hook, delivered)
+		return nil
+	})
+	if err == nil {
+		_, err = await(ctx, channel)
+		if err != nil {
+			_, _ = subscription.Unsubscribe()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("registering %s: %w", hook, err)
+	}
+	broker.registered[hook] = subscription
+	return nil
+}
+
+// publish sends a delivery to the streams following hook, which may be a
+// pattern matching the delivered hook.
+func (broker *broker) publish(hook string, delivered juno.HookEvent) {
+	encoded, err := json.Marshal(delivered.Data)
+	if err != nil {
+		return
+	}
+	broker.Lock()
+	defer broker.Unlock()
+	for channel := range broker.subscribers[hook] {
+		select {
+		case channel <- event{hook: delivered.Hook, data: encoded}:
+		default:
+		}
+	}
+}
+
+func (bridge *Bridge) stream(w nethttp.ResponseWriter, r *nethttp.Request) {
+	hooks := r.URL.Query()["hook"]
+	if len(hooks) == 0 {
+		writeError(w, nethttp.StatusBadRequest, "at least one hook query parameter is required")
+		return
+	}
+	flusher, ok := w.(nethttp.Flusher)
+	if !ok {
+		writeError(w, nethttp.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	registerCtx, cancel := context.WithTimeout(r.Context(), bridge.Timeout)
+	events, err := bridge.events.subscribe(registerCtx, hooks)
+	cancel()
+	if err != nil {
+		writeFailure(w, err)
+		return
+	}
+	defer bridge.events.unsubscribe(events, hooks)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(nethttp.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(bridge.KeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case delivered := <-events:
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", delivered.hook, delivered.data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
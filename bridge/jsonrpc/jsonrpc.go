@@ -0,0 +1,319 @@
+// Package jsonrpc serves JSON-RPC 2.0 through a module. The method is the
+// juno function to call, such as "orders.place", with named params as its
+// arguments. The reserved method "juno.triggerHook" takes {"hook", "data"}
+// params and triggers the hook from the bridging module.
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/utils/error_codes"
+)
+
+const TriggerHookMethod = "juno.triggerHook"
+
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+	// GatewayError is used for gateway errors without a closer JSON-RPC
+	// equivalent, Timeout for calls that took too long and FunctionError for
+	// errors returned by the called function.
+	GatewayError  = -32000
+	Timeout       = -32001
+	FunctionError = -32002
+)
+
+const maxMessageSize = 16 * 1024 * 1024
+
+// Id is left empty when the request has no id, which makes it a notification,
+// and holds "null" for an explicit null id, which still gets an answer.
+type request struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+type Bridge struct {
+	module *juno.JunoModule
+	// Timeout bounds every call and trigger.
+	Timeout time.Duration
+}
+
+// New creates a bridge that acts through module, which must be initialized.
+func New(module *juno.JunoModule) *Bridge {
+	return &Bridge{module: module, Timeout: 30 * time.Second}
+}
+
+// Handle answers a single request or a batch. It returns nil when nothing
+// needs to be sent back, which is the case for notifications.
+func (bridge *Bridge) Handle(ctx context.Context, payload []byte) []byte {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) > 0 && payload[0] == '[' {
+		return bridge.handleBatch(ctx, payload)
+	}
+
+	var single json.RawMessage
+	if err := json.Unmarshal(payload, &single); err != nil {
+		return encode(failed(nil, &Error{Code: ParseError, Message: "parse error"}))
+	}
+	result := bridge.handleOne(ctx, single)
+	if result == nil {
+		return nil
+	}
+	return encode(result)
+}
+
+func (bridge *Bridge) handleBatch(ctx context.Context, payload []byte) []byte {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return encode(failed(nil, &Error{Code: ParseError, Message: "parse error"}))
+	}
+	if len(batch) == 0 {
+		return encode(failed(nil, &Error{Code: InvalidRequest, Message: "empty batch"}))
+	}
+
+	results := make([]*response, len(batch))
+	var done sync.WaitGroup
+	for i, message := range batch {
+		done.Add(1)
+		go func(i int, message json.RawMessage) {
+			defer done.Done()
+			results[i] = bridge.handleOne(ctx, message)
+		}(i, message)
+	}
+	done.Wait()
+
+	answered := []*response{}
+	for _, result := range results {
+		if result != nil {
+			answered = append(answered, result)
+		}
+	}
+	if len(answered) == 0 {
+		return nil
+	}
+	return encode(answered)
+}
+
+func (bridge *Bridge) handleOne(ctx context.Context, message json.RawMessage) *response {
+	// Fields that did parse are kept on error, so an invalid request still
+	// gets its id back when it had a usable one.
+	var call request
+	malformed := json.Unmarshal(message, &call) != nil
+	if !validId(call.Id) {
+		return failed(nil, &Error{Code: InvalidRequest, Message: "invalid request"})
+	}
+	if malformed || call.Version != "2.0" || call.Method == "" {
+		return failed(call.Id, &Error{Code: InvalidRequest, Message: "invalid request"})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bridge.Timeout)
+	defer cancel()
+	result, err := bridge.dispatch(ctx, call)
+	if call.Id == nil {
+		return nil
+	}
+	if err != nil {
+		return failed(call.Id, err)
+	}
+	if result == nil {
+		// A response must carry either a result or an error.
+		result = json.RawMessage("null")
+	}
+	return &response{Version: "2.0", Result: result, Id: call.Id}
+}
+
+func (bridge *Bridge) dispatch(ctx context.Context, call request) (interface{}, *Error) {
+	if call.Method == TriggerHookMethod {
+		var params struct {
+			Hook string      `json:"hook"`
+			Data interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(call.Params, &params); err != nil || params.Hook == "" {
+			return nil, &Error{Code: InvalidParams, Message: "expected {\"hook\": string, \"data\": any}"}
+		}
+		channel, err := bridge.module.TriggerHookContext(ctx, params.Hook, params.Data)
+		if err != nil {
+			return nil, &Error{Code: InternalError, Message: err.Error()}
+		}
+		if _, err := await(ctx, channel); err != nil {
+			return nil, err
+		}
+		return true, nil
+	}
+
+	if !strings.Contains(call.Method, ".") {
+		return nil, &Error{Code: MethodNotFound, Message: "method must be module.function"}
+	}
+	var args map[string]interface{}
+	if len(call.Params) > 0 && string(call.Params) != "null" {
+		if err := json.Unmarshal(call.Params, &args); err != nil {
+			return nil, &Error{Code: InvalidParams, Message: "params must be an object"}
+		}
+	}
+	channel, err := bridge.module.CallFunctionContext(ctx, call.Method, args)
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+	return await(ctx, channel)
+}
+
+func await(ctx context.Context, channel chan interface{}) (interface{}, *Error) {
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			return nil, toError(err)
+		}
+		return result, nil
+	case <-ctx.Done():
+		return nil, toError(ctx.Err())
+	}
+}
+
+// toError maps errors delivered by the module to JSON-RPC error objects.
+// Gateway errors carry their juno code and name as data.
+func toError(err error) *Error {
+	var gatewayError *juno.GatewayError
+	var functionError *juno.FunctionError
+	switch {
+	case errors.As(err, &functionError):
+		return &Error{Code: FunctionError, Message: functionError.Message}
+	case errors.As(err, &gatewayError):
+		data := map[string]interface{}{"code": gatewayError.Code, "name": error_codes.Name(gatewayError.Code)}
+		switch gatewayError.Code {
+		case error_codes.UnknownModule, error_codes.UnknownFunction:
+			return &Error{Code: MethodNotFound, Message: "method not found", Data: data}
+		case error_codes.MalformedRequest:
+			return &Error{Code: InvalidParams, Message: "invalid params", Data: data}
+		default:
+			return &Error{Code: GatewayError, Message: err.Error(), Data: data}
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: Timeout, Message: "timed out"}
+	default:
+		return &Error{Code: InternalError, Message: err.Error()}
+	}
+}
+
+// validId reports whether id is absent or a string, number or null, the only
+// ids JSON-RPC allows.
+func validId(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	switch id[0] {
+	case '{', '[', 't', 'f':
+		return false
+	default:
+		return true
+	}
+}
+
+// failed answers with err. A nil id is sent as null.
+func failed(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{Version: "2.0", Error: err, Id: id}
+}
+
+func encode(value interface{}) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(failed(nil, &Error{Code: InternalError, Message: err.Error()}))
+	}
+	return data
+}
+
+// ServeHTTP answers JSON-RPC posted over HTTP. Requests consisting only of
+// notifications get 204 No Content.
+func (bridge *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(payload) > maxMessageSize {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = w.Write(encode(failed(nil, &Error{Code: InvalidRequest, Message: "request too large"})))
+		return
+	}
+	answer := bridge.Handle(r.Context(), payload)
+	if answer == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(answer)
+}
+
+// ServeStream reads newline separated requests from in, such as stdin, and
+// writes a line to out for each answer until in ends or ctx is done.
+// Requests are handled concurrently, so answers may come out of order.
+func (bridge *Bridge) ServeStream(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	var lock sync.Mutex
+	var pending sync.WaitGroup
+	defer pending.Wait()
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := append([]byte{}, scanner.Bytes()...)
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		pending.Add(1)
+		go func() {
+			defer pending.Done()
+			answer := bridge.Handle(ctx, line)
+			if answer == nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			_, _ = out.Write(append(answer, '\n'))
+		}()
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,114 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/bridge/jsonrpc"
+	"github.com/bytesonus/juno-go/gateway"
+)
+
+// startBridge serves a "server" module with a failing function through a
+// gateway, and bridges through a second module.
+func startBridge(t *testing.T) *jsonrpc.Bridge {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gateway.New().Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	modules := []*juno.JunoModule{}
+	for _, moduleId := range []string{"server", "bridge"} {
+		module := juno.Default(listener.Addr().String())
+		channel, err := module.Initialize(moduleId, "1.0.0", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		await(t, channel)
+		t.Cleanup(func() { module.Close() })
+		modules = append(modules, &module)
+	}
+	channel, err := modules[0].DeclareTypedFunction("fail", func(ctx context.Context, args struct{}) error {
+		return errors.New("out of stock")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	await(t, channel)
+	return jsonrpc.New(modules[1])
+}
+
+func await(t *testing.T, channel chan interface{}) {
+	t.Helper()
+	select {
+	case <-channel:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a response")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	bridge := startBridge(t)
+	tests := []struct {
+		name    string
+		request string
+		// answer is the expected response, or empty for none.
+		answer string
+	}{
+		{"parse error", `{"jsonrpc":`, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error"},"id":null}`},
+		{"invalid version echoes the id", `{"jsonrpc":"1.0","method":"server.fail","id":7}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":7}`},
+		{"malformed method echoes the id", `{"jsonrpc":"2.0","method":1,"id":"a"}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":"a"}`},
+		{"object id", `{"jsonrpc":"2.0","method":"server.fail","id":{}}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`},
+		{"not an object", `1`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request"},"id":null}`},
+		{"null id is answered", `{"jsonrpc":"2.0","method":"nodot","id":null}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"method must be module.function"},"id":null}`},
+		{"missing id is a notification", `{"jsonrpc":"2.0","method":"nodot"}`, ``},
+		{"function error", `{"jsonrpc":"2.0","method":"server.fail","id":1}`, `{"jsonrpc":"2.0","error":{"code":-32002,"message":"out of stock"},"id":1}`},
+		{"notification batch", `[{"jsonrpc":"2.0","method":"nodot"},{"jsonrpc":"2.0","method":"server.fail"}]`, ``},
+		{"batch", `[{"jsonrpc":"2.0","method":"nodot"},{"jsonrpc":"2.0","method":"nodot","id":2}]`, `[{"jsonrpc":"2.0","error":{"code":-32601,"message":"method must be module.function"},"id":2}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			answer := bridge.Handle(context.Background(), []byte(test.request))
+			if test.answer == "" {
+				if answer != nil {
+					t.Fatalf("got %s, want no answer", answer)
+				}
+				return
+			}
+			if string(answer) != test.answer {
+				t.Fatalf("got %s, want %s", answer, test.answer)
+			}
+		})
+	}
+}
+
+func TestServeHTTPRejectsLargeRequests(t *testing.T) {
+	bridge := startBridge(t)
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(bytes.Repeat([]byte(" "), 16*1024*1024+1)))
+	recorder := httptest.NewRecorder()
+	bridge.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+	var answer struct {
+		Error jsonrpc.Error `json:"error"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &answer); err != nil {
+		t.Fatal(err)
+	}
+	if answer.Error.Message != "request too large" {
+		t.Fatalf("got %q, want request too large", answer.Error.Message)
+	}
+}
@@ -0,0 +1,158 @@
+package juno_go
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	chunkFunction  = "__chunk"
+	chunkEnvelope  = "__chunked"
+	chunkRetention = time.Minute
+	// chunkFetchTimeout bounds fetching a single chunk, so that a chunk owner
+	// that went away can't hold up the caller forever.
+	chunkFetchTimeout = 10 * time.Second
+	// chunkFrameOverhead is what a response frame adds to its data, with
+	// room for the request id and fields added by the gateway.
+	chunkFrameOverhead = 256
+)
+
+type ChunkListType struct {
+	sync.Mutex
+	m map[string][][]byte
+}
+
+// SetResponseChunkSize makes function responses that don't fit in a frame of
+// size bytes get split into chunks sent in frames of at most size bytes, which
+// the caller fetches and reassembles transparently. It must be called before
+// Initialize. A size of 0 disables chunking.
+func (module *JunoModule) SetResponseChunkSize(size int) {
+	module.chunkSize = size
+}
+
+// chunkBytes is how much of the encoded response fits in a chunk, once base64
+// and the response frame around it are accounted for.
+func (module *JunoModule) chunkBytes() int {
+	size := (module.chunkSize - chunkFrameOverhead) / 4 * 3
+	if size < 3 {
+		return 3
+	}
+	return size
+}
+
+func (module *JunoModule) chunkResponse(requestId string, data interface{}) interface{} {
+	if module.chunkSize <= 0 {
+		return data
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		module.logger.Warn("couldn't measure response for chunking", "requestId", requestId, "error", err)
+		return data
+	}
+	if len(payload)+chunkFrameOverhead <= module.chunkSize {
+		return data
+	}
+
+	// The chunks are kept under an id of their own rather than the request
+	// id, which other modules could guess.
+	id, err := transferId()
+	if err != nil {
+		module.logger.Warn("couldn't create chunk transfer id", "requestId", requestId, "error", err)
+		return data
+	}
+	chunks := [][]byte{}
+	for len(payload) > 0 {
+		size := module.chunkBytes()
+		if size > len(payload) {
+			size = len(payload)
+		}
+		chunks = append(chunks, payload[:size])
+		payload = payload[size:]
+	}
+
+	module.chunks.Lock()
+	module.chunks.m[id] = chunks
+	module.chunks.Unlock()
+	time.AfterFunc(chunkRetention, func() {
+		module.chunks.Lock()
+		delete(module.chunks.m, id)
+		module.chunks.Unlock()
+	})
+
+	return map[string]interface{}{
+		chunkEnvelope: map[string]interface{}{
+			"module": module.protocol.GetModuleId(),
+			"id":     id,
+			"count":  len(chunks),
+		},
+	}
+}
+
+func (module *JunoModule) serveChunk(args map[string]interface{}) interface{} {
+	id, _ := args["id"].(string)
+	index, _ := args["index"].(float64)
+
+	module.chunks.Lock()
+	defer module.chunks.Unlock()
+	chunks := module.chunks.m[id]
+	if int(index) < 0 || int(index) >= len(chunks) {
+		return nil
+	}
+	if int(index) == len(chunks)-1 {
+		delete(module.chunks.m, id)
+	}
+	return chunks[int(index)]
+}
+
+// collectChunks reassembles a chunked response. Chunks are fetched under the
+// caller's ctx, each within chunkFetchTimeout, and failures are reported as a
+// *ChunkError.
+func (module *JunoModule) collectChunks(ctx context.Context, envelope map[string]interface{}) interface{} {
+	owner, _ := envelope["module"].(string)
+	id, _ := envelope["id"].(string)
+	count, _ := envelope["count"].(float64)
+
+	payload := []byte{}
+	for index := 0; index < int(count); index++ {
+		chunk, err := module.fetchChunk(ctx, owner, id, index)
+		if err != nil {
+			return &ChunkError{TransferId: id, Err: err}
+		}
+		payload = append(payload, chunk...)
+	}
+
+	var value interface{}
+	err := json.Unmarshal(payload, &value)
+	if err != nil {
+		return &ChunkError{TransferId: id, Err: err}
+	}
+	return value
+}
+
+func (module *JunoModule) fetchChunk(ctx context.Context, owner, id string, index int) ([]byte, error) {
+	channel, err := module.CallFunctionContext(ctx, owner+"."+chunkFunction, map[string]interface{}{
+		"id":    id,
+		"index": index,
+	}, WithTimeout(chunkFetchTimeout))
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	select {
+	case result = <-channel:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err, ok := result.(error); ok {
+		return nil, err
+	}
+	encoded, ok := result.(string)
+	if !ok {
+		return nil, fmt.Errorf("chunk %d is no longer available", index)
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
@@ -0,0 +1,77 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkedResponse(t *testing.T) {
+	address := startGateway(t)
+	payload := strings.Repeat("0123456789", 1000)
+	server := startModule(t, address, "server", func(module *JunoModule) {
+		module.SetResponseChunkSize(1024)
+	})
+	await(t, mustSend(t)(server.DeclareFunction("big", func(map[string]interface{}) interface{} {
+		return payload
+	})))
+	// Chunk frames must fit the limit the chunk size was chosen for.
+	client := startModule(t, address, "client", func(module *JunoModule) {
+		if err := module.SetMessageSizeLimits(1024, 1024); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	result := await(t, mustSend(t)(client.CallFunction("server.big", nil)))
+	if result != payload {
+		t.Fatalf("got %.40v, want the %d byte payload", result, len(payload))
+	}
+
+	// Without __chunk the transfer can't complete, which must fail the call
+	// instead of leaving it waiting.
+	await(t, mustSend(t)(server.UndeclareFunction(chunkFunction)))
+	channel, err := client.CallFunctionContext(context.Background(), "server.big", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunkError *ChunkError
+	result = await(t, channel)
+	if err, ok := result.(error); !ok || !errors.As(err, &chunkError) {
+		t.Fatalf("got %.40v, want a *ChunkError", result)
+	}
+	if chunkError.TransferId == "" {
+		t.Error("ChunkError has no transfer id")
+	}
+}
+
+func TestResultsShapedLikeEnvelopes(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server", func(module *JunoModule) {
+		module.SetResponseChunkSize(1024)
+	})
+	client := startModule(t, address, "client")
+
+	results := map[string]interface{}{
+		"chunked": map[string]interface{}{chunkEnvelope: map[string]interface{}{"module": "server", "id": "x", "count": float64(3)}},
+		"escaped": map[string]interface{}{escapedEnvelope: "value"},
+		"large":   map[string]interface{}{chunkEnvelope: strings.Repeat("x", 4096)},
+		"plain":   map[string]interface{}{"__other": true},
+		"failure": map[string]interface{}{failureEnvelope: "not a failure"},
+		"error":   map[string]interface{}{"error": "not a failure either"},
+		"stream":  map[string]interface{}{streamEnvelope: map[string]interface{}{"module": "server", "id": "x"}},
+	}
+	for name, want := range results {
+		want := want
+		await(t, mustSend(t)(server.DeclareFunction(name, func(map[string]interface{}) interface{} {
+			return want
+		})))
+	}
+	for name, want := range results {
+		got := await(t, mustSend(t)(client.CallFunction("server."+name, nil)))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %.60v, want %.60v", name, got, want)
+		}
+	}
+}
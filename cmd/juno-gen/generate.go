@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/bytesonus/juno-go/schema"
+)
+
+const helpers = `
+// Errors returned by a Server reach clients as a *juno.FunctionError.
+func junoCall(ctx context.Context, module *juno.JunoModule, function string, args interface{}, result interface{}, opts []juno.CallOption) error {
+	input := map[string]interface{}{}
+	if args != nil {
+		if err := junoConvert(args, &input); err != nil {
+			return err
+		}
+	}
+	channel, err := module.CallFunctionContext(ctx, function, input, opts...)
+	if err != nil {
+		return err
+	}
+
+	var response interface{}
+	select {
+	case response = <-channel:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err, ok := response.(error); ok {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return junoConvert(response, result)
+}
+
+func junoConvert(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+`
+
+type generator struct {
+	bytes.Buffer
+	schema *schema.Schema
+}
+
+func (generator *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&generator.Buffer, format, args...)
+}
+
+func generate(definition *schema.Schema, packageName, source string) ([]byte, error) {
+	generator := &generator{schema: definition}
+	if err := generator.checkNames(); err != nil {
+		return nil, err
+	}
+	generator.printf("// Code generated by juno-gen from %s. DO NOT EDIT.\n\n", source)
+	generator.printf("package %s\n\n", packageName)
+	generator.printf("import (\n\t\"context\"\n\t\"encoding/json\"\n\n\tjuno \"github.com/bytesonus/juno-go\"\n)\n\n")
+	generator.printf("const ModuleId = %q\n\n", definition.Module)
+
+	generator.types()
+	generator.client()
+	generator.server()
+	generator.printf("%s", helpers)
+
+	code, err := format.Source(generator.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return code, nil
+}
+
+// checkNames rejects schemas whose names map to the same Go identifier, such
+// as a type "PlaceArgs" next to a function "place", or "a_b" next to "a-b".
+func (generator *generator) checkNames() error {
+	problems := []string{}
+	declare := func(scope map[string]string, name, from string) {
+		if !token.IsIdentifier(name) {
+			problems = append(problems, fmt.Sprintf("%s has no valid Go name", from))
+			return
+		}
+		if previous, ok := scope[name]; ok {
+			problems = append(problems, fmt.Sprintf("%s and %s both generate %s", previous, from, name))
+			return
+		}
+		scope[name] = from
+	}
+	fields := func(from string, fields map[string]string) {
+		scope := map[string]string{}
+		for _, field := range schema.FieldNames(fields) {
+			declare(scope, goName(field), from+" field "+field)
+		}
+	}
+
+	global := map[string]string{}
+	for _, name := range []string{"ModuleId", "Client", "NewClient", "Server", "Register"} {
+		global[name] = "the generated " + name
+	}
+	methods := map[string]string{}
+	for _, name := range generator.schema.TypeNames() {
+		declare(global, goName(name), "type "+name)
+		fields("type "+name, generator.schema.Types[name].Fields)
+	}
+	for _, name := range generator.schema.FunctionNames() {
+		function := generator.schema.Functions[name]
+		declare(methods, goName(name), "function "+name)
+		if len(function.Args) > 0 {
+			declare(global, argsName(name), "function "+name)
+			fields("function "+name, function.Args)
+		}
+	}
+	for _, name := range generator.schema.HookNames() {
+		declare(methods, "On"+goName(name), "hook "+name)
+		declare(global, "Trigger"+goName(name), "hook "+name)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("go names: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (generator *generator) types() {
+	for _, name := range generator.schema.TypeNames() {
+		definition := generator.schema.Types[name]
+		generator.comment(definition.Description)
+		generator.structure(goName(name), definition.Fields)
+	}
+	for _, name := range generator.schema.FunctionNames() {
+		function := generator.schema.Functions[name]
+		if len(function.Args) > 0 {
+			generator.structure(argsName(name), function.Args)
+		}
+	}
+}
+
+func (generator *generator) structure(name string, fields map[string]string) {
+	generator.printf("type %s struct {\n", name)
+	for _, field := range schema.FieldNames(fields) {
+		generator.printf("\t%s %s `json:\"%s\"`\n", goName(field), goType(fields[field]), field)
+	}
+	generator.printf("}\n\n")
+}
+
+func (generator *generator) client() {
+	module := generator.schema.Module
+	generator.printf("// Client calls the functions of the %s module and listens to its hooks.\n", module)
+	generator.printf("type Client struct {\n\tmodule *juno.JunoModule\n}\n\n")
+	generator.printf("func NewClient(module *juno.JunoModule) *Client {\n\treturn &Client{module: module}\n}\n\n")
+
+	for _, name := range generator.schema.FunctionNames() {
+		function := generator.schema.Functions[name]
+		generator.comment(function.Description)
+		generator.printf("func (client *Client) %s(%s, opts ...juno.CallOption) %s {\n", goName(name), params(name, function), results(function))
+		args := "nil"
+		if len(function.Args) > 0 {
+			args = "args"
+		}
+		if function.Result == "" {
+			generator.printf("\treturn junoCall(ctx, client.module, ModuleId+%q, %s, nil, opts)\n}\n\n", "."+name, args)
+			continue
+		}
+		generator.printf("\tvar result %s\n", goType(function.Result))
+		generator.printf("\terr := junoCall(ctx, client.module, ModuleId+%q, %s, &result, opts)\n", "."+name, args)
+		generator.printf("\treturn result, err\n}\n\n")
+	}
+
+	for _, name := range generator.schema.HookNames() {
+		hook := generator.schema.Hooks[name]
+		generator.comment(hook.Description)
+		if hook.Data == "" {
+			generator.printf("func (client *Client) On%s(handler func(ctx context.Context) error) (chan interface{}, *juno.Subscription, error) {\n", goName(name))
+			generator.printf("\treturn client.module.RegisterHookContext(ModuleId+%q, func(ctx context.Context, data interface{}) error {\n", "."+name)
+			generator.printf("\t\treturn handler(ctx)\n\t})\n}\n\n")
+			continue
+		}
+		generator.printf("func (client *Client) On%s(handler func(ctx context.Context, data %s) error) (chan interface{}, *juno.Subscription, error) {\n", goName(name), goType(hook.Data))
+		generator.printf("\treturn client.module.RegisterHookContext(ModuleId+%q, func(ctx context.Context, data interface{}) error {\n", "."+name)
+		generator.printf("\t\tvar value %s\n", goType(hook.Data))
+		generator.printf("\t\tif err := junoConvert(data, &value); err != nil {\n\t\t\treturn err\n\t\t}\n")
+		generator.printf("\t\treturn handler(ctx, value)\n\t})\n}\n\n")
+	}
+}
+
+func (generator *generator) server() {
+	module := generator.schema.Module
+	generator.printf("// Server is implemented by the %s module. Register declares its functions.\n", module)
+	generator.printf("type Server interface {\n")
+	for _, name := range generator.schema.FunctionNames() {
+		function := generator.schema.Functions[name]
+		generator.comment(function.Description)
+		generator.printf("\t%s(%s) %s\n", goName(name), params(name, function), results(function))
+	}
+	generator.printf("}\n\n")
+
+	generator.printf("// Register declares the functions of server with their types, so that they\n")
+	generator.printf("// show up in __describe.\n")
+	generator.printf("func Register(module *juno.JunoModule, server Server) error {\n")
+	for _, name := range generator.schema.FunctionNames() {
+		function := generator.schema.Functions[name]
+		input, call := "_ struct{}", "ctx"
+		if len(function.Args) > 0 {
+			input, call = "input "+argsName(name), "ctx, input"
+		}
+		generator.printf("\tif _, err := module.DeclareTypedFunction(%q, func(ctx context.Context, %s) %s {\n", name, input, results(function))
+		generator.printf("\t\treturn server.%s(%s)\n", goName(name), call)
+		generator.printf("\t}); err != nil {\n\t\treturn err\n\t}\n")
+	}
+	generator.printf("\treturn nil\n}\n\n")
+
+	for _, name := range generator.schema.HookNames() {
+		hook := generator.schema.Hooks[name]
+		if hook.Data == "" {
+			generator.printf("func Trigger%s(module *juno.JunoModule, opts ...juno.CallOption) (chan interface{}, error) {\n", goName(name))
+			generator.printf("\treturn module.TriggerHook(%q, nil, opts...)\n}\n\n", name)
+			continue
+		}
+		generator.printf("func Trigger%s(module *juno.JunoModule, data %s, opts ...juno.CallOption) (chan interface{}, error) {\n", goName(name), goType(hook.Data))
+		generator.printf("\treturn module.TriggerHook(%q, data, opts...)\n}\n\n", name)
+	}
+}
+
+func (generator *generator) comment(description string) {
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		if line != "" {
+			generator.printf("// %s\n", strings.TrimSpace(line))
+		}
+	}
+}
+
+func params(name string, function schema.Function) string {
+	if len(function.Args) == 0 {
+		return "ctx context.Context"
+	}
+	return "ctx context.Context, args " + argsName(name)
+}
+
+func results(function schema.Function) string {
+	if function.Result == "" {
+		return "error"
+	}
+	return "(" + goType(function.Result) + ", error)"
+}
+
+func argsName(function string) string {
+	return goName(function) + "Args"
+}
+
+func goType(expression string) string {
+	switch {
+	case strings.HasPrefix(expression, "[]"):
+		return "[]" + goType(expression[2:])
+	case strings.HasPrefix(expression, "map[string]"):
+		return "map[string]" + goType(expression[len("map[string]"):])
+	}
+	switch expression {
+	case "string", "bool":
+		return expression
+	case "int":
+		return "int64"
+	case "float":
+		return "float64"
+	case "any":
+		return "interface{}"
+	case "object":
+		return "map[string]interface{}"
+	default:
+		return goName(expression)
+	}
+}
+
+// goName turns a schema name such as "print_hello-world" into an exported Go
+// identifier like "PrintHelloWorld".
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for i, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		parts[i] = string(runes)
+	}
+	return strings.Join(parts, "")
+}
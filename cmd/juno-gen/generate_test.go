@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bytesonus/juno-go/schema"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestGenerateGolden(t *testing.T) {
+	definition, err := schema.Load(filepath.Join("testdata", "orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := generate(definition, "orders", "orders.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "orders_juno.go.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, want) {
+		t.Errorf("generated code differs from %s, rerun with -update if that's intended", golden)
+	}
+}
+
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module with the go command")
+	}
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := ioutil.ReadFile(filepath.Join("testdata", "orders_juno.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/orders\n\ngo 1.14\n\n" +
+			"require github.com/bytesonus/juno-go v0.0.0\n\n" +
+			"replace github.com/bytesonus/juno-go => " + root + "\n",
+		"orders_juno.go": string(code),
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	command := exec.Command(goCommand, "vet", ".")
+	command.Dir = dir
+	command.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("generated code doesn't build: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateRejectsNameCollisions(t *testing.T) {
+	for _, test := range []struct {
+		name, schema, problem string
+	}{
+		{
+			"args type",
+			`{"module": "orders", "types": {"PlaceArgs": {"fields": {}}}, "functions": {"place": {"args": {"item": "string"}}}}`,
+			"type PlaceArgs and function place both generate PlaceArgs",
+		},
+		{
+			"reserved name",
+			`{"module": "orders", "types": {"Client": {"fields": {}}}}`,
+			"the generated Client and type Client both generate Client",
+		},
+		{
+			"separators",
+			`{"module": "orders", "functions": {"a_b": {}, "a-b": {}}}`,
+			"function a-b and function a_b both generate AB",
+		},
+		{
+			"fields",
+			`{"module": "orders", "types": {"Order": {"fields": {"item_id": "string", "itemId": "string"}}}}`,
+			"type Order field itemId and type Order field item_id both generate ItemId",
+		},
+		{
+			"hook listener",
+			`{"module": "orders", "functions": {"OnCreated": {}}, "hooks": {"created": {}}}`,
+			"function OnCreated and hook created both generate OnCreated",
+		},
+		{
+			"field name",
+			`{"module": "orders", "types": {"Order": {"fields": {"1st": "string"}}}}`,
+			"type Order field 1st has no valid Go name",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			definition, err := schema.Parse([]byte(test.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = generate(definition, "orders", "orders.json")
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("got %v, want an error about %q", err, test.problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Command juno-gen generates a typed client and server interface from a
+// module schema. Use it from go generate:
+//
+//	//go:generate juno-gen -schema orders.json -out orders_juno.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bytesonus/juno-go/schema"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "path of the module schema (JSON)")
+	out := flag.String("out", "", "output file (default: <schema name>_juno.go)")
+	packageName := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	flag.Parse()
+
+	if *schemaPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = strings.TrimSuffix(*schemaPath, filepath.Ext(*schemaPath)) + "_juno.go"
+	}
+	if *packageName == "" {
+		*packageName = "main"
+	}
+
+	if err := run(*schemaPath, *out, *packageName); err != nil {
+		fmt.Fprintln(os.Stderr, "juno-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, out, packageName string) error {
+	definition, err := schema.Load(schemaPath)
+	if err != nil {
+		return err
+	}
+	code, err := generate(definition, packageName, filepath.Base(schemaPath))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bytesonus/juno-go/bench"
+	"github.com/bytesonus/juno-go/gateway"
+)
+
+func runBench(options globalOptions, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	config := bench.Config{}
+	flags.IntVar(&config.Modules, "modules", 4, "number of modules to start")
+	flags.DurationVar(&config.Duration, "duration", 10*time.Second, "how long to drive traffic")
+	flags.IntVar(&config.CallRate, "call-rate", 0, "function calls per second across all modules, 0 for unlimited")
+	flags.IntVar(&config.HookRate, "hook-rate", 0, "hook triggers per second across all modules, 0 to disable")
+	flags.IntVar(&config.Concurrency, "concurrency", 8, "outstanding calls per module")
+	flags.IntVar(&config.PayloadSize, "payload", 64, "payload size in bytes")
+	flags.DurationVar(&config.Timeout, "timeout", 10*time.Second, "per-call timeout")
+	local := flags.Bool("local", false, "benchmark against an in-process gateway stand-in")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("bench doesn't take positional arguments")
+	}
+
+	if *local {
+		directory, err := ioutil.TempDir("", "juno-bench")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(directory)
+
+		socket := filepath.Join(directory, "juno.sock")
+		standIn, err := gateway.Listen("unix", socket)
+		if err != nil {
+			return err
+		}
+		defer standIn.Close()
+		options.url = ""
+		options.socket = socket
+	}
+	config.Dial = options.module
+
+	report, err := bench.Run(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("duration        %v\n", report.Duration.Round(time.Millisecond))
+	fmt.Printf("calls           %d (%d failed)\n", report.Calls, report.CallErrors)
+	fmt.Printf("calls/s         %.1f\n", report.CallsPerSecond)
+	fmt.Printf("latency p50     %v\n", report.Latency.P50)
+	fmt.Printf("latency p90     %v\n", report.Latency.P90)
+	fmt.Printf("latency p99     %v\n", report.Latency.P99)
+	fmt.Printf("latency max     %v\n", report.Latency.Max)
+	if config.HookRate > 0 {
+		fmt.Printf("hooks           %d triggered, %d received\n", report.HooksTriggered, report.HooksReceived)
+		fmt.Printf("hooks/s         %.1f\n", report.HooksPerSecond)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+)
+
+func runCall(options globalOptions, args []string) error {
+	flags := flag.NewFlagSet("call", flag.ExitOnError)
+	arguments := flags.String("args", "{}", "function arguments as a JSON object")
+	timeout := flags.Duration("timeout", 30*time.Second, "time to wait for the response")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("call expects exactly one <module.function>")
+	}
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal([]byte(*arguments), &decoded)
+	if err != nil {
+		return fmt.Errorf("invalid --args: %v", err)
+	}
+
+	module, err := options.connect()
+	if err != nil {
+		return err
+	}
+	defer module.Close()
+
+	channel, err := module.CallFunction(positional[0], decoded, juno.WithTimeout(*timeout))
+	if err != nil {
+		return err
+	}
+	result := <-channel
+	if err, ok := result.(error); ok {
+		return err
+	}
+	return printJson(result)
+}
+
+func runTrigger(options globalOptions, args []string) error {
+	flags := flag.NewFlagSet("trigger", flag.ExitOnError)
+	data := flags.String("data", "null", "hook data as JSON")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("trigger expects exactly one <hook>")
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal([]byte(*data), &decoded)
+	if err != nil {
+		return fmt.Errorf("invalid --data: %v", err)
+	}
+
+	module, err := options.connect()
+	if err != nil {
+		return err
+	}
+	defer module.Close()
+
+	channel, err := module.TriggerHook(positional[0], decoded)
+	if err != nil {
+		return err
+	}
+	if err, ok := (<-channel).(error); ok {
+		return err
+	}
+	return nil
+}
+
+func runListen(options globalOptions, args []string) error {
+	flags := flag.NewFlagSet("listen", flag.ExitOnError)
+	hooks, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return errors.New("listen expects at least one <module.hook>")
+	}
+
+	module, err := options.connect()
+	if err != nil {
+		return err
+	}
+	defer module.Close()
+
+	var output sync.Mutex
+	for _, hook := range hooks {
+		channel, _, err := module.RegisterHookPattern(hook, func(ctx context.Context, event juno.HookEvent) error {
+			output.Lock()
+			defer output.Unlock()
+			return printJson(map[string]interface{}{
+				"hook": event.Hook,
+				"data": event.Data,
+				"meta": event.Meta,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if err, ok := (<-channel).(error); ok {
+			return fmt.Errorf("listening to %s: %w", hook, err)
+		}
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	return nil
+}
+
+func printJson(value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(encoded))
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bytesonus/juno-go/bridge/jsonrpc"
+)
+
+func runJsonRpc(options globalOptions, args []string) error {
+	flags := flag.NewFlagSet("jsonrpc", flag.ExitOnError)
+	address := flags.String("http", "", "serve JSON-RPC over HTTP on this address instead of stdio")
+	timeout := flags.Duration("timeout", 30*time.Second, "time to wait for each call")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errors.New("jsonrpc takes no arguments")
+	}
+
+	module, err := options.connect()
+	if err != nil {
+		return err
+	}
+	defer module.Close()
+
+	bridge := jsonrpc.New(module)
+	bridge.Timeout = *timeout
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	if *address == "" {
+		return bridge.ServeStream(ctx, os.Stdin, os.Stdout)
+	}
+	server := &http.Server{Addr: *address, Handler: bridge}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	fmt.Fprintln(os.Stderr, "serving JSON-RPC on", *address)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
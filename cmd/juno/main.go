@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/connection"
+	"github.com/bytesonus/juno-go/protocol"
+)
+
+const usage = `usage: juno [flags] <command> [arguments]
+
+Commands:
+  call <module.function> [--args JSON] [--timeout DURATION]
+  trigger <hook> [--data JSON]
+  listen <module.hook or pattern>...
+  jsonrpc [--http ADDRESS] [--timeout DURATION]
+  bench [--local] [--modules N] [--duration D] [--call-rate N] [--hook-rate N]
+
+Flags:
+`
+
+type globalOptions struct {
+	socket   string
+	url      string
+	moduleId string
+	version  string
+}
+
+func main() {
+	options := globalOptions{}
+	flags := flag.NewFlagSet("juno", flag.ExitOnError)
+	flags.StringVar(&options.socket, "socket", "./juno.sock", "path of the gateway's unix socket")
+	flags.StringVar(&options.url, "url", "", "address of the gateway, as host:port, tcp://host:port or unix://path")
+	flags.StringVar(&options.moduleId, "module-id", fmt.Sprintf("juno-cli-%d", os.Getpid()), "module id to register as")
+	flags.StringVar(&options.version, "version", "1.0.0", "module version to register with")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "call":
+		err = runCall(options, args)
+	case "trigger":
+		err = runTrigger(options, args)
+	case "listen":
+		err = runListen(options, args)
+	case "jsonrpc":
+		err = runJsonRpc(options, args)
+	case "bench":
+		err = runBench(options, args)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "juno:", err)
+		os.Exit(1)
+	}
+}
+
+func (options globalOptions) module() (*juno.JunoModule, error) {
+	var link connection.BaseConnection = connection.NewUnixSocketConnection(options.socket)
+	if options.url != "" {
+		var err error
+		link, err = connection.FromUrl(options.url)
+		if err != nil {
+			return nil, err
+		}
+	}
+	module := juno.NewJunoModule(protocol.NewJsonProtocol(), link)
+	return &module, nil
+}
+
+func (options globalOptions) connect() (*juno.JunoModule, error) {
+	module, err := options.module()
+	if err != nil {
+		return nil, err
+	}
+	channel, err := module.Initialize(options.moduleId, options.version, nil)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			return nil, err
+		}
+	case <-time.After(10 * time.Second):
+		return nil, fmt.Errorf("timed out registering with the gateway")
+	}
+	return module, nil
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, which the flag package doesn't allow by itself.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -1,10 +1,143 @@
 package connection
 
+import (
+	"bufio"
+	"errors"
+	"math"
+	"sync/atomic"
+
+	"github.com/bytesonus/juno-go/logging"
+)
+
+const DefaultMaxMessageSize = 16 * 1024 * 1024
+
+var ErrMessageTooLarge = errors.New("message exceeds the maximum allowed size")
+
+// frameExcerpt is how much of the start and end of a discarded frame is kept.
+const frameExcerpt = 256
+
 type DataHandler func([]byte)
 
+// ErrorHandler is called with frames that were read but can't be delivered,
+// such as a *FrameTooLargeError.
+type ErrorHandler func(error)
+
+// FrameTooLargeError is reported for inbound frames over the limit. Head and
+// Tail keep the start and end of the discarded frame, so that the message it
+// carried can still be identified.
+type FrameTooLargeError struct {
+	Size int
+	Head []byte
+	Tail []byte
+}
+
+func (err *FrameTooLargeError) Error() string {
+	return ErrMessageTooLarge.Error()
+}
+
+func (err *FrameTooLargeError) Is(target error) bool {
+	return target == ErrMessageTooLarge
+}
+
 type BaseConnection interface {
 	SetupConnection() error
 	CloseConnection() error
 	Send([]byte) error
 	SetOnDataHandler(DataHandler)
 }
+
+// SizeLimitedConnection is implemented by connections that can cap the size
+// of the frames they read and write. A limit of 0 disables the check.
+type SizeLimitedConnection interface {
+	SetMaxInboundSize(int)
+	SetMaxOutboundSize(int)
+}
+
+// ErrorReportingConnection is implemented by connections that report frames
+// they had to drop.
+type ErrorReportingConnection interface {
+	SetOnErrorHandler(ErrorHandler)
+}
+
+// LoggableConnection is implemented by connections that report errors they
+// can't return to a caller, such as a failing read loop.
+type LoggableConnection interface {
+	SetLogger(logging.Logger)
+}
+
+// readFrame reads a single newline-terminated frame. Frames larger than limit
+// are discarded up to their terminating newline and a *FrameTooLargeError is
+// returned, so a peer can never make the reader buffer more than limit bytes.
+func readFrame(reader *bufio.Reader, limit int) ([]byte, error) {
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if limit > 0 && len(frame)+len(chunk) > limit {
+			return nil, discardFrame(reader, append(frame, chunk...), err)
+		}
+		frame = append(frame, chunk...)
+		if err == nil {
+			return frame, nil
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+}
+
+func discardFrame(reader *bufio.Reader, read []byte, err error) error {
+	tooLarge := &FrameTooLargeError{
+		Size: len(read),
+		Head: append([]byte{}, read[:min(len(read), frameExcerpt)]...),
+	}
+	tail := keepTail(nil, read)
+	for err == bufio.ErrBufferFull {
+		var chunk []byte
+		chunk, err = reader.ReadSlice('\n')
+		tooLarge.Size += len(chunk)
+		tail = keepTail(tail, chunk)
+	}
+	if err != nil {
+		return err
+	}
+	tooLarge.Tail = tail
+	return tooLarge
+}
+
+func keepTail(tail []byte, chunk []byte) []byte {
+	tail = append(tail, chunk...)
+	if len(tail) > frameExcerpt {
+		tail = append([]byte{}, tail[len(tail)-frameExcerpt:]...)
+	}
+	return tail
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// sizeLimit is a frame size limit that can be changed while the connection
+// reads and writes.
+type sizeLimit struct {
+	value int32
+}
+
+func newSizeLimit(size int) *sizeLimit {
+	limit := &sizeLimit{}
+	limit.set(size)
+	return limit
+}
+
+func (limit *sizeLimit) set(size int) {
+	if size > math.MaxInt32 {
+		size = math.MaxInt32
+	}
+	atomic.StoreInt32(&limit.value, int32(size))
+}
+
+func (limit *sizeLimit) get() int {
+	return int(atomic.LoadInt32(&limit.value))
+}
@@ -0,0 +1,104 @@
+package connection
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadFrame(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		name   string
+		input  string
+		limit  int
+		frames []string
+		errs   []error
+	}{
+		{
+			name:   "single frame",
+			input:  "{}\n",
+			limit:  10,
+			frames: []string{"{}\n"},
+			errs:   []error{nil},
+		},
+		{
+			name:   "frame at the limit",
+			input:  "12345\n",
+			limit:  6,
+			frames: []string{"12345\n"},
+			errs:   []error{nil},
+		},
+		{
+			name:   "oversized frame is skipped",
+			input:  long + "\n{}\n",
+			limit:  10,
+			frames: []string{"", "{}\n"},
+			errs:   []error{ErrMessageTooLarge, nil},
+		},
+		{
+			name:   "no limit",
+			input:  long + "\n",
+			limit:  0,
+			frames: []string{long + "\n"},
+			errs:   []error{nil},
+		},
+		{
+			name:   "unterminated frame",
+			input:  "{}",
+			limit:  10,
+			frames: []string{""},
+			errs:   []error{io.EOF},
+		},
+		{
+			name:   "oversized unterminated frame",
+			input:  long,
+			limit:  10,
+			frames: []string{""},
+			errs:   []error{io.EOF},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The smallest buffer makes frames span several reads.
+			reader := bufio.NewReaderSize(strings.NewReader(test.input), 16)
+			for i, want := range test.frames {
+				frame, err := readFrame(reader, test.limit)
+				if !errors.Is(err, test.errs[i]) {
+					t.Fatalf("frame %d: got error %v, want %v", i, err, test.errs[i])
+				}
+				if string(frame) != want {
+					t.Fatalf("frame %d: got %q, want %q", i, frame, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFrameKeepsExcerpts(t *testing.T) {
+	head := `{"requestId":"a-1","data":"`
+	tail := `","type":4}`
+	input := head + strings.Repeat("x", 4096) + tail + "\n"
+	reader := bufio.NewReaderSize(strings.NewReader(input), 16)
+
+	_, err := readFrame(reader, 1024)
+	tooLarge, ok := err.(*FrameTooLargeError)
+	if !ok {
+		t.Fatalf("got error %v, want a *FrameTooLargeError", err)
+	}
+	if tooLarge.Size != len(input) {
+		t.Errorf("got size %d, want %d", tooLarge.Size, len(input))
+	}
+	if !strings.HasPrefix(string(tooLarge.Head), head) {
+		t.Errorf("head %q doesn't start the frame", tooLarge.Head)
+	}
+	if !strings.HasSuffix(string(tooLarge.Tail), tail+"\n") {
+		t.Errorf("tail %q doesn't end the frame", tooLarge.Tail)
+	}
+	if len(tooLarge.Head) > frameExcerpt || len(tooLarge.Tail) > frameExcerpt {
+		t.Errorf("excerpts are longer than %d bytes", frameExcerpt)
+	}
+}
@@ -3,23 +3,38 @@ package connection
 import (
 	"bufio"
 	"errors"
-	"fmt"
+	"io"
 	"net"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/bytesonus/juno-go/logging"
 )
 
 type InetSocketConnection struct {
-	bindAddr string
-	port uint16
+	bindAddr    string
+	port        uint16
 	client      net.Conn
 	dataHandler DataHandler
+	onError     ErrorHandler
+	maxInbound  *sizeLimit
+	maxOutbound *sizeLimit
+	logger      logging.Logger
+	closed      int32
 }
 
 func NewInetSocketConnection(host string, port uint16) *InetSocketConnection {
-	return &InetSocketConnection{bindAddr: host, port: port}
+	return &InetSocketConnection{
+		bindAddr:    host,
+		port:        port,
+		maxInbound:  newSizeLimit(DefaultMaxMessageSize),
+		maxOutbound: newSizeLimit(DefaultMaxMessageSize),
+		logger:      logging.Nop(),
+	}
 }
 
 func (connection *InetSocketConnection) SetupConnection() error {
-	client, err := net.Dial("tcp", fmt.Sprintf("%s:%d", connection.bindAddr, connection.port))
+	client, err := net.Dial("tcp", net.JoinHostPort(connection.bindAddr, strconv.Itoa(int(connection.port))))
 	if err != nil {
 		return err
 	}
@@ -35,6 +50,7 @@ func (connection *InetSocketConnection) CloseConnection() error {
 		return errors.New("client isn't initialized yet. Did you forget to call SetupConnection()")
 	}
 
+	atomic.StoreInt32(&connection.closed, 1)
 	err := connection.client.Close()
 	if err != nil {
 		return err
@@ -48,6 +64,10 @@ func (connection *InetSocketConnection) Send(data []byte) error {
 		return errors.New("client isn't initialized yet. Did you forget to call SetupConnection()")
 	}
 
+	if limit := connection.maxOutbound.get(); limit > 0 && len(data) > limit {
+		return ErrMessageTooLarge
+	}
+
 	_, err := connection.client.Write(data)
 	if err != nil {
 		return err
@@ -60,11 +80,42 @@ func (connection *InetSocketConnection) SetOnDataHandler(dataHandler DataHandler
 	connection.dataHandler = dataHandler
 }
 
+func (connection *InetSocketConnection) SetOnErrorHandler(onError ErrorHandler) {
+	connection.onError = onError
+}
+
+func (connection *InetSocketConnection) SetMaxInboundSize(size int) {
+	connection.maxInbound.set(size)
+}
+
+func (connection *InetSocketConnection) SetMaxOutboundSize(size int) {
+	connection.maxOutbound.set(size)
+}
+
+func (connection *InetSocketConnection) SetLogger(logger logging.Logger) {
+	connection.logger = logger
+}
+
 func (connection *InetSocketConnection) readLoop() {
 	reader := bufio.NewReader(connection.client)
 	for {
-		line, err := reader.ReadBytes(byte('\n'))
+		line, err := readFrame(reader, connection.maxInbound.get())
+		if tooLarge, ok := err.(*FrameTooLargeError); ok {
+			connection.logger.Warn("dropped oversized frame", "size", tooLarge.Size, "limit", connection.maxInbound.get())
+			if connection.onError != nil {
+				go connection.onError(tooLarge)
+			}
+			continue
+		}
 		if err != nil {
+			if atomic.LoadInt32(&connection.closed) == 1 {
+				return
+			}
+			if err == io.EOF {
+				connection.logger.Info("connection closed by peer")
+			} else {
+				connection.logger.Error("read loop stopped", "error", err)
+			}
 			return
 		}
 		go connection.onData(line)
@@ -76,4 +127,3 @@ func (connection *InetSocketConnection) onData(data []byte) {
 		connection.dataHandler(data)
 	}
 }
-
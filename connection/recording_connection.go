@@ -0,0 +1,141 @@
+package connection
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bytesonus/juno-go/logging"
+)
+
+type Direction string
+
+const (
+	Inbound  Direction = "in"
+	Outbound Direction = "out"
+)
+
+// Frame is a single recorded message. Data holds the raw bytes exactly as
+// they were read from or written to the wire.
+type Frame struct {
+	Time      time.Time `json:"time"`
+	Direction Direction `json:"direction"`
+	Data      []byte    `json:"data"`
+}
+
+// RecordingConnection wraps another connection and writes every frame it
+// sends and receives to a recording, one JSON encoded Frame per line.
+type RecordingConnection struct {
+	sync.Mutex
+	inner   BaseConnection
+	encoder *json.Encoder
+	logger  logging.Logger
+	err     error
+}
+
+func NewRecordingConnection(inner BaseConnection, out io.Writer) *RecordingConnection {
+	return &RecordingConnection{
+		inner:   inner,
+		encoder: json.NewEncoder(out),
+		logger:  logging.Nop(),
+	}
+}
+
+func (connection *RecordingConnection) SetupConnection() error {
+	return connection.inner.SetupConnection()
+}
+
+func (connection *RecordingConnection) CloseConnection() error {
+	return connection.inner.CloseConnection()
+}
+
+func (connection *RecordingConnection) Send(data []byte) error {
+	// Holding the lock across the write keeps a response from being
+	// recorded ahead of the request that caused it.
+	connection.Lock()
+	defer connection.Unlock()
+	err := connection.inner.Send(data)
+	if err != nil {
+		return err
+	}
+	connection.record(Outbound, data)
+	return nil
+}
+
+func (connection *RecordingConnection) SetOnDataHandler(dataHandler DataHandler) {
+	connection.inner.SetOnDataHandler(func(data []byte) {
+		connection.Lock()
+		connection.record(Inbound, data)
+		connection.Unlock()
+		dataHandler(data)
+	})
+}
+
+func (connection *RecordingConnection) SetOnErrorHandler(onError ErrorHandler) {
+	if reporting, ok := connection.inner.(ErrorReportingConnection); ok {
+		reporting.SetOnErrorHandler(onError)
+	}
+}
+
+func (connection *RecordingConnection) SetMaxInboundSize(size int) {
+	if limited, ok := connection.inner.(SizeLimitedConnection); ok {
+		limited.SetMaxInboundSize(size)
+	}
+}
+
+func (connection *RecordingConnection) SetMaxOutboundSize(size int) {
+	if limited, ok := connection.inner.(SizeLimitedConnection); ok {
+		limited.SetMaxOutboundSize(size)
+	}
+}
+
+func (connection *RecordingConnection) SetLogger(logger logging.Logger) {
+	connection.logger = logger
+	if loggable, ok := connection.inner.(LoggableConnection); ok {
+		loggable.SetLogger(logger)
+	}
+}
+
+// Err returns the first error encountered while writing the recording.
+func (connection *RecordingConnection) Err() error {
+	connection.Lock()
+	defer connection.Unlock()
+	return connection.err
+}
+
+// record must be called with the connection locked.
+func (connection *RecordingConnection) record(direction Direction, data []byte) {
+	if connection.err != nil {
+		return
+	}
+	err := connection.encoder.Encode(Frame{
+		Time:      time.Now(),
+		Direction: direction,
+		Data:      data,
+	})
+	if err != nil {
+		connection.err = err
+		connection.logger.Error("recording stopped", "error", err)
+	}
+}
+
+// ReadRecording parses a recording written by a RecordingConnection.
+func ReadRecording(in io.Reader) ([]Frame, error) {
+	frames := []Frame{}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*DefaultMaxMessageSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var frame Frame
+		err := json.Unmarshal(scanner.Bytes(), &frame)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, scanner.Err()
+}
@@ -0,0 +1,249 @@
+package connection
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/bytesonus/juno-go/utils/request_keys"
+)
+
+// ReplayConnection plays a recorded session back to a module. Inbound frames
+// are delivered one after another in their recorded order, each one as soon
+// as every outbound frame recorded before it has been sent again by the
+// module and the previous inbound frame has been handled.
+//
+// Outbound frames are matched to the earliest unmatched recorded frame with
+// the same content, such as the same function and arguments. Request ids,
+// metadata and deadlines differ from run to run, so they are left out of the
+// comparison, and the request ids of inbound frames are rewritten to the live
+// ones. This assumes the frames were encoded by the JSON protocol.
+type ReplayConnection struct {
+	sync.Mutex
+	frames      []Frame
+	matched     []bool
+	cursor      int
+	ids         map[string]string
+	sent        [][]byte
+	unexpected  [][]byte
+	dataHandler DataHandler
+	queue       [][]byte
+	busy        bool
+	turn        int
+	running     int
+	ready       *sync.Cond
+	done        chan struct{}
+	closed      bool
+}
+
+func NewReplayConnection(frames []Frame) *ReplayConnection {
+	connection := &ReplayConnection{
+		frames:  frames,
+		matched: make([]bool, len(frames)),
+		ids:     make(map[string]string),
+		done:    make(chan struct{}),
+	}
+	connection.ready = sync.NewCond(&connection.Mutex)
+	return connection
+}
+
+func LoadReplayConnection(in io.Reader) (*ReplayConnection, error) {
+	frames, err := ReadRecording(in)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayConnection(frames), nil
+}
+
+func (connection *ReplayConnection) SetupConnection() error {
+	connection.Lock()
+	defer connection.Unlock()
+	go connection.deliver()
+	connection.advance()
+	return nil
+}
+
+func (connection *ReplayConnection) CloseConnection() error {
+	connection.Lock()
+	defer connection.Unlock()
+	connection.closed = true
+	connection.ready.Broadcast()
+	return nil
+}
+
+func (connection *ReplayConnection) Send(data []byte) error {
+	connection.Lock()
+	defer connection.Unlock()
+	if connection.closed {
+		return errors.New("replay connection is closed")
+	}
+
+	frame := append([]byte{}, data...)
+	connection.sent = append(connection.sent, frame)
+	if !connection.match(frame) {
+		connection.unexpected = append(connection.unexpected, frame)
+	}
+	// A handler that sent a request may be waiting for its response, which
+	// is one of the frames still to come.
+	connection.busy = false
+	connection.ready.Signal()
+	connection.advance()
+	return nil
+}
+
+func (connection *ReplayConnection) SetOnDataHandler(dataHandler DataHandler) {
+	connection.dataHandler = dataHandler
+}
+
+// Done is closed once every recorded frame has been handled or matched.
+func (connection *ReplayConnection) Done() <-chan struct{} {
+	return connection.done
+}
+
+// Sent returns the frames sent by the module so far.
+func (connection *ReplayConnection) Sent() [][]byte {
+	connection.Lock()
+	defer connection.Unlock()
+	return append([][]byte{}, connection.sent...)
+}
+
+// Unexpected returns the frames sent by the module that had no counterpart
+// in the recording.
+func (connection *ReplayConnection) Unexpected() [][]byte {
+	connection.Lock()
+	defer connection.Unlock()
+	return append([][]byte{}, connection.unexpected...)
+}
+
+func (connection *ReplayConnection) match(data []byte) bool {
+	live := decodeFrame(data)
+	if live == nil {
+		return false
+	}
+	for i := connection.cursor; i < len(connection.frames); i++ {
+		if connection.matched[i] || connection.frames[i].Direction != Outbound {
+			continue
+		}
+		recorded := decodeFrame(connection.frames[i].Data)
+		if recorded == nil || !sameContent(recorded, live) {
+			continue
+		}
+		connection.matched[i] = true
+		recordedId, _ := recorded[request_keys.RequestId].(string)
+		liveId, _ := live[request_keys.RequestId].(string)
+		if recordedId != "" {
+			connection.ids[recordedId] = liveId
+		}
+		return true
+	}
+	return false
+}
+
+// advance must be called with the connection locked.
+func (connection *ReplayConnection) advance() {
+	for connection.cursor < len(connection.frames) {
+		frame := connection.frames[connection.cursor]
+		if frame.Direction == Outbound {
+			if !connection.matched[connection.cursor] {
+				return
+			}
+		} else if connection.dataHandler != nil && !connection.closed {
+			connection.queue = append(connection.queue, connection.rewrite(frame.Data))
+			connection.ready.Signal()
+		}
+		connection.cursor++
+	}
+	connection.finish()
+}
+
+// deliver hands inbound frames to the data handler one at a time, so that
+// a replay runs the same way every time. The next frame waits until the
+// handler returns or sends a frame of its own, since a handler may be waiting
+// for the response to a request it sent.
+func (connection *ReplayConnection) deliver() {
+	connection.Lock()
+	defer connection.Unlock()
+	for {
+		for (len(connection.queue) == 0 || connection.busy) && !connection.closed {
+			connection.ready.Wait()
+		}
+		if connection.closed {
+			return
+		}
+		data := connection.queue[0]
+		connection.queue = connection.queue[1:]
+		connection.busy = true
+		connection.running++
+		connection.turn++
+		turn := connection.turn
+		go func() {
+			connection.dataHandler(data)
+			connection.Lock()
+			defer connection.Unlock()
+			connection.running--
+			if connection.turn == turn {
+				connection.busy = false
+				connection.ready.Signal()
+			}
+			connection.finish()
+		}()
+	}
+}
+
+// finish must be called with the connection locked.
+func (connection *ReplayConnection) finish() {
+	if connection.cursor < len(connection.frames) || len(connection.queue) > 0 || connection.running > 0 {
+		return
+	}
+	select {
+	case <-connection.done:
+	default:
+		close(connection.done)
+	}
+}
+
+// sameContent compares two messages, leaving out what changes between runs.
+func sameContent(recorded, live map[string]interface{}) bool {
+	return reflect.DeepEqual(stable(recorded), stable(live))
+}
+
+func stable(message map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(message))
+	for key, value := range message {
+		switch key {
+		case request_keys.RequestId, request_keys.Meta, request_keys.Deadline:
+			continue
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+func (connection *ReplayConnection) rewrite(data []byte) []byte {
+	message := decodeFrame(data)
+	if message == nil {
+		return data
+	}
+	recordedId, _ := message[request_keys.RequestId].(string)
+	liveId, ok := connection.ids[recordedId]
+	if !ok || liveId == recordedId {
+		return data
+	}
+	message[request_keys.RequestId] = liveId
+	rewritten, err := json.Marshal(message)
+	if err != nil {
+		return data
+	}
+	return append(rewritten, '\n')
+}
+
+func decodeFrame(data []byte) map[string]interface{} {
+	var message map[string]interface{}
+	err := json.Unmarshal(data, &message)
+	if err != nil {
+		return nil
+	}
+	return message
+}
@@ -0,0 +1,147 @@
+package connection
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func frame(direction Direction, message string) Frame {
+	return Frame{Direction: direction, Data: []byte(message + "\n")}
+}
+
+func TestReplayMatching(t *testing.T) {
+	recorded := []Frame{
+		frame(Outbound, `{"type":3,"requestId":"r1","function":"a.f","arguments":{"x":1},"meta":{"traceparent":"00-1"},"deadline":100}`),
+		frame(Inbound, `{"type":4,"requestId":"r1","data":"ok"}`),
+	}
+	tests := []struct {
+		name    string
+		sent    string
+		matched bool
+	}{
+		{"same content", `{"type":3,"requestId":"live","function":"a.f","arguments":{"x":1},"meta":{"traceparent":"00-1"},"deadline":100}`, true},
+		{"different volatile fields", `{"type":3,"requestId":"live","function":"a.f","arguments":{"x":1},"meta":{"traceparent":"00-2"},"deadline":200}`, true},
+		{"without volatile fields", `{"type":3,"requestId":"live","function":"a.f","arguments":{"x":1}}`, true},
+		{"different arguments", `{"type":3,"requestId":"live","function":"a.f","arguments":{"x":2}}`, false},
+		{"different function", `{"type":3,"requestId":"live","function":"a.g","arguments":{"x":1}}`, false},
+		{"different type", `{"type":7,"requestId":"live","hook":"a.f"}`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replay := NewReplayConnection(recorded)
+			delivered := make(chan []byte, 1)
+			replay.SetOnDataHandler(func(data []byte) {
+				delivered <- data
+			})
+			if err := replay.SetupConnection(); err != nil {
+				t.Fatal(err)
+			}
+			defer replay.CloseConnection()
+			if err := replay.Send([]byte(test.sent + "\n")); err != nil {
+				t.Fatal(err)
+			}
+
+			if !test.matched {
+				if len(replay.Unexpected()) != 1 {
+					t.Fatalf("got %d unexpected frames, want 1", len(replay.Unexpected()))
+				}
+				select {
+				case data := <-delivered:
+					t.Fatalf("delivered %s for an unmatched frame", data)
+				case <-time.After(20 * time.Millisecond):
+				}
+				return
+			}
+			if len(replay.Unexpected()) != 0 {
+				t.Fatalf("frame wasn't matched")
+			}
+			select {
+			case data := <-delivered:
+				var response map[string]interface{}
+				if err := json.Unmarshal(data, &response); err != nil {
+					t.Fatal(err)
+				}
+				if response["requestId"] != "live" {
+					t.Errorf("got request id %v, want it rewritten to live", response["requestId"])
+				}
+			case <-time.After(time.Second):
+				t.Fatal("response wasn't delivered")
+			}
+		})
+	}
+}
+
+func TestReplayDeliversInOrder(t *testing.T) {
+	recorded := []Frame{}
+	for i := 0; i < 20; i++ {
+		recorded = append(recorded, frame(Inbound, `{"type":8,"requestId":"h","hook":"a.`+string(rune('a'+i))+`"}`))
+	}
+	replay := NewReplayConnection(recorded)
+
+	var lock sync.Mutex
+	order := []string{}
+	replay.SetOnDataHandler(func(data []byte) {
+		var message map[string]interface{}
+		_ = json.Unmarshal(data, &message)
+		// Early frames take longest, which reorders concurrent delivery.
+		time.Sleep(time.Duration(20-len(order)) * 100 * time.Microsecond)
+		lock.Lock()
+		order = append(order, message["hook"].(string))
+		lock.Unlock()
+	})
+	if err := replay.SetupConnection(); err != nil {
+		t.Fatal(err)
+	}
+	defer replay.CloseConnection()
+
+	select {
+	case <-replay.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay didn't finish")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for i, hook := range order {
+		if want := "a." + string(rune('a'+i)); hook != want {
+			t.Fatalf("frame %d: got %s, want %s", i, hook, want)
+		}
+	}
+	if len(order) != len(recorded) {
+		t.Fatalf("delivered %d frames, want %d", len(order), len(recorded))
+	}
+}
+
+func TestReplayReleasesWaitingHandler(t *testing.T) {
+	// The handler of the first frame calls a function and waits for the
+	// response, which is replayed after the call it sends.
+	recorded := []Frame{
+		frame(Inbound, `{"type":8,"requestId":"h","hook":"a.started"}`),
+		frame(Outbound, `{"type":3,"requestId":"r1","function":"b.f","arguments":{}}`),
+		frame(Inbound, `{"type":4,"requestId":"r1","data":"ok"}`),
+	}
+	replay := NewReplayConnection(recorded)
+	response := make(chan []byte, 1)
+	replay.SetOnDataHandler(func(data []byte) {
+		var message map[string]interface{}
+		_ = json.Unmarshal(data, &message)
+		if message["hook"] == "a.started" {
+			_ = replay.Send([]byte(`{"type":3,"requestId":"live","function":"b.f","arguments":{}}` + "\n"))
+			<-response
+			return
+		}
+		response <- data
+	})
+	if err := replay.SetupConnection(); err != nil {
+		t.Fatal(err)
+	}
+	defer replay.CloseConnection()
+
+	select {
+	case <-replay.Done():
+	case <-time.After(time.Second):
+		t.Fatal("replay deadlocked")
+	}
+}
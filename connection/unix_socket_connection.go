@@ -3,17 +3,31 @@ package connection
 import (
 	"bufio"
 	"errors"
+	"io"
 	"net"
+	"sync/atomic"
+
+	"github.com/bytesonus/juno-go/logging"
 )
 
 type UnixSocketConnection struct {
 	socketPath  string
 	client      net.Conn
 	dataHandler DataHandler
+	onError     ErrorHandler
+	maxInbound  *sizeLimit
+	maxOutbound *sizeLimit
+	logger      logging.Logger
+	closed      int32
 }
 
 func NewUnixSocketConnection(socketPath string) *UnixSocketConnection {
-	return &UnixSocketConnection{socketPath: socketPath}
+	return &UnixSocketConnection{
+		socketPath:  socketPath,
+		maxInbound:  newSizeLimit(DefaultMaxMessageSize),
+		maxOutbound: newSizeLimit(DefaultMaxMessageSize),
+		logger:      logging.Nop(),
+	}
 }
 
 func (connection *UnixSocketConnection) SetupConnection() error {
@@ -33,6 +47,7 @@ func (connection *UnixSocketConnection) CloseConnection() error {
 		return errors.New("client isn't initialized yet. Did you forget to call SetupConnection()")
 	}
 
+	atomic.StoreInt32(&connection.closed, 1)
 	err := connection.client.Close()
 	if err != nil {
 		return err
@@ -46,6 +61,10 @@ func (connection *UnixSocketConnection) Send(data []byte) error {
 		return errors.New("client isn't initialized yet. Did you forget to call SetupConnection()")
 	}
 
+	if limit := connection.maxOutbound.get(); limit > 0 && len(data) > limit {
+		return ErrMessageTooLarge
+	}
+
 	_, err := connection.client.Write(data)
 	if err != nil {
 		return err
@@ -58,11 +77,42 @@ func (connection *UnixSocketConnection) SetOnDataHandler(dataHandler DataHandler
 	connection.dataHandler = dataHandler
 }
 
+func (connection *UnixSocketConnection) SetOnErrorHandler(onError ErrorHandler) {
+	connection.onError = onError
+}
+
+func (connection *UnixSocketConnection) SetMaxInboundSize(size int) {
+	connection.maxInbound.set(size)
+}
+
+func (connection *UnixSocketConnection) SetMaxOutboundSize(size int) {
+	connection.maxOutbound.set(size)
+}
+
+func (connection *UnixSocketConnection) SetLogger(logger logging.Logger) {
+	connection.logger = logger
+}
+
 func (connection *UnixSocketConnection) readLoop() {
 	reader := bufio.NewReader(connection.client)
 	for {
-		line, err := reader.ReadBytes(byte('\n'))
+		line, err := readFrame(reader, connection.maxInbound.get())
+		if tooLarge, ok := err.(*FrameTooLargeError); ok {
+			connection.logger.Warn("dropped oversized frame", "size", tooLarge.Size, "limit", connection.maxInbound.get())
+			if connection.onError != nil {
+				go connection.onError(tooLarge)
+			}
+			continue
+		}
 		if err != nil {
+			if atomic.LoadInt32(&connection.closed) == 1 {
+				return
+			}
+			if err == io.EOF {
+				connection.logger.Info("connection closed by peer")
+			} else {
+				connection.logger.Error("read loop stopped", "error", err)
+			}
 			return
 		}
 		go connection.onData(line)
@@ -0,0 +1,36 @@
+package connection
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// FromUrl creates the connection described by url, which is one of
+// unix:///path/to/juno.sock, tcp://host:port, host:port or a plain socket
+// path.
+func FromUrl(url string) (BaseConnection, error) {
+	if strings.HasPrefix(url, "unix://") {
+		return NewUnixSocketConnection(strings.TrimPrefix(url, "unix://")), nil
+	}
+	if strings.HasPrefix(url, "tcp://") {
+		return inetFromAddress(strings.TrimPrefix(url, "tcp://"))
+	}
+	if _, err := net.ResolveTCPAddr("tcp", url); err == nil {
+		return inetFromAddress(url)
+	}
+	return NewUnixSocketConnection(url), nil
+}
+
+func inetFromAddress(address string) (BaseConnection, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	number, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+	return NewInetSocketConnection(host, uint16(number)), nil
+}
@@ -0,0 +1,113 @@
+package juno_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bytesonus/juno-go/schema"
+)
+
+// Version is the version of this library, reported by __describe.
+const Version = "0.2.0"
+
+const describeFunction = "__describe"
+
+// Description is what a module's __describe function returns. Functions lists
+// declared functions, with argument and result types for those declared with
+// DeclareTypedFunction. RegisteredHooks lists the hooks the module listens to.
+type Description struct {
+	schema.Schema
+	Version         string   `json:"version"`
+	LibraryVersion  string   `json:"libraryVersion"`
+	RegisteredHooks []string `json:"registeredHooks"`
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// DeclareTypedFunction declares fn, which must look like
+// func(context.Context, Args) (Result, error) or func(context.Context, Args) error
+// with Args a struct. Arguments are decoded into Args, and the argument and
+// result types are published through __describe. A returned error reaches
+// the caller as a *FunctionError.
+func (module *JunoModule) DeclareTypedFunction(fnName string, fn interface{}) (chan interface{}, error) {
+	value := reflect.ValueOf(fn)
+	t := value.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.In(0) != contextType || t.In(1).Kind() != reflect.Struct ||
+		t.NumOut() < 1 || t.NumOut() > 2 || t.Out(t.NumOut()-1) != errorType {
+		return nil, errors.New("typed function must be func(context.Context, Args) (Result, error) or func(context.Context, Args) error")
+	}
+
+	types := map[string]schema.Type{}
+	described := schema.Function{Args: schema.FieldsOf(t.In(1), types)}
+	if t.NumOut() == 2 {
+		described.Result = schema.TypeOf(t.Out(0), types)
+	}
+
+	module.functions.Lock()
+	module.functions.schemas[fnName] = described
+	for name, definition := range types {
+		module.functions.types[name] = definition
+	}
+	module.functions.Unlock()
+
+	return module.DeclareFunctionContext(fnName, func(ctx context.Context, args map[string]interface{}) interface{} {
+		input := reflect.New(t.In(1))
+		if err := convert(args, input.Interface()); err != nil {
+			return failure(err)
+		}
+		results := value.Call([]reflect.Value{reflect.ValueOf(ctx), input.Elem()})
+		if err, _ := results[len(results)-1].Interface().(error); err != nil {
+			return failure(err)
+		}
+		if len(results) == 1 {
+			return nil
+		}
+		return results[0].Interface()
+	})
+}
+
+func (module *JunoModule) describe(args map[string]interface{}) interface{} {
+	description := Description{
+		Schema: schema.Schema{
+			Module:    module.moduleId,
+			Types:     map[string]schema.Type{},
+			Functions: map[string]schema.Function{},
+		},
+		Version:         module.version,
+		LibraryVersion:  Version,
+		RegisteredHooks: []string{},
+	}
+
+	module.functions.RLock()
+	for name := range module.functions.m {
+		if !strings.HasPrefix(name, "__") {
+			description.Functions[name] = module.functions.schemas[name]
+		}
+	}
+	for name, definition := range module.functions.types {
+		description.Types[name] = definition
+	}
+	module.functions.RUnlock()
+
+	module.hookListeners.RLock()
+	for hook := range module.hookListeners.m {
+		description.RegisteredHooks = append(description.RegisteredHooks, hook)
+	}
+	module.hookListeners.RUnlock()
+	sort.Strings(description.RegisteredHooks)
+
+	return description
+}
+
+func convert(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,96 @@
+package juno_go
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+)
+
+const (
+	// escapedEnvelope wraps function results that happen to look like one of
+	// the envelopes this library uses for its own state, such as a chunked
+	// response, so that the caller gets them back unchanged.
+	escapedEnvelope = "__escaped"
+	// failureEnvelope carries the message of a function that failed.
+	failureEnvelope = "__error"
+)
+
+var reservedEnvelopes = map[string]bool{
+	chunkEnvelope:   true,
+	escapedEnvelope: true,
+	failureEnvelope: true,
+	streamEnvelope:  true,
+}
+
+// functionFailure fails a call, reaching the caller as a *FunctionError.
+type functionFailure struct {
+	Message string `json:"__error"`
+}
+
+func failure(err error) interface{} {
+	return functionFailure{Message: err.Error()}
+}
+
+// escapeResult wraps a function result that would be mistaken for an
+// envelope. Envelopes built by the library itself are structs rather than
+// maps, so they pass through.
+func escapeResult(value interface{}) interface{} {
+	if _, ok := envelopeOf(value); ok {
+		return map[string]interface{}{escapedEnvelope: value}
+	}
+	return value
+}
+
+// envelopeOf returns the reserved key of a value shaped like an envelope.
+func envelopeOf(value interface{}) (string, bool) {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Map || reflected.Type().Key().Kind() != reflect.String || reflected.Len() != 1 {
+		return "", false
+	}
+	key := reflected.MapKeys()[0].String()
+	return key, reservedEnvelopes[key]
+}
+
+// decodeResponse turns the data of a function response back into the
+// handler's result. Failures become a *FunctionError and stream envelopes a
+// *StreamHandle, which a result of the handler's making never decodes to.
+func (module *JunoModule) decodeResponse(ctx context.Context, data interface{}) interface{} {
+	key, ok := envelopeOf(data)
+	if !ok {
+		return data
+	}
+	body := data.(map[string]interface{})[key]
+	switch key {
+	case escapedEnvelope:
+		return body
+	case failureEnvelope:
+		message, _ := body.(string)
+		return &FunctionError{Message: message}
+	case streamEnvelope:
+		handle := &StreamHandle{}
+		if err := convert(body, handle); err != nil {
+			return err
+		}
+		return handle
+	case chunkEnvelope:
+		envelope, _ := body.(map[string]interface{})
+		value := module.collectChunks(ctx, envelope)
+		if _, failed := value.(error); failed {
+			return value
+		}
+		return module.decodeResponse(ctx, value)
+	}
+	return data
+}
+
+// transferId returns an id nobody can guess for data handed out by id, such
+// as a stream or an upload, since knowing the id is what lets a module read
+// it.
+func transferId() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
@@ -0,0 +1,43 @@
+package juno_go
+
+import (
+	"fmt"
+
+	"github.com/bytesonus/juno-go/utils/error_codes"
+)
+
+// GatewayError is delivered on a request's channel when the gateway answers
+// it with an error message. Code is one of the error_codes constants.
+type GatewayError struct {
+	RequestId string
+	Code      uint32
+}
+
+func (err *GatewayError) Error() string {
+	return fmt.Sprintf("gateway error: %s (code %d)", error_codes.Name(err.Code), err.Code)
+}
+
+// ChunkError is delivered when a chunked response couldn't be reassembled.
+// TransferId is the random id the chunks were kept under.
+type ChunkError struct {
+	TransferId string
+	Err        error
+}
+
+func (err *ChunkError) Error() string {
+	return fmt.Sprintf("chunked response %s: %v", err.TransferId, err.Err)
+}
+
+func (err *ChunkError) Unwrap() error {
+	return err.Err
+}
+
+// FunctionError is delivered when the called function failed, such as a
+// typed function returning an error. Message is the error's text.
+type FunctionError struct {
+	Message string
+}
+
+func (err *FunctionError) Error() string {
+	return err.Message
+}
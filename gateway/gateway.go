@@ -0,0 +1,339 @@
+package gateway
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/bytesonus/juno-go/models"
+	"github.com/bytesonus/juno-go/protocol"
+	"github.com/bytesonus/juno-go/utils/error_codes"
+	"github.com/bytesonus/juno-go/utils/hook_pattern"
+	"github.com/bytesonus/juno-go/utils/semver"
+)
+
+// Gateway is a minimal in-process stand-in for the juno gateway, meant for
+// tests, benchmarks and local development. It routes function calls and
+// hooks between modules and keeps no state beyond the lifetime of the
+// connections. It also goes beyond juno: it forwards the meta and deadline
+// fields of calls and hooks, matches hook patterns and answers request types
+// 11-14, so code relying on those works here but not against juno.
+type Gateway struct {
+	sync.Mutex
+	listener net.Listener
+	protocol protocol.BaseProtocol
+	modules  map[string]*session
+	hooks    map[string]map[*session]bool
+	pending  map[string]*session
+}
+
+type session struct {
+	sync.Mutex
+	conn net.Conn
+	// outbox holds encoded messages until write sends them, so that the
+	// gateway never waits for a module while holding its lock.
+	outbox       [][]byte
+	ready        *sync.Cond
+	closed       bool
+	moduleId     string
+	version      string
+	dependencies map[string]string
+	functions    map[string]bool
+	hooks        map[string]bool
+	activated    bool
+}
+
+func New() *Gateway {
+	return &Gateway{
+		protocol: protocol.NewJsonProtocol(),
+		modules:  make(map[string]*session),
+		hooks:    make(map[string]map[*session]bool),
+		pending:  make(map[string]*session),
+	}
+}
+
+// Listen starts a gateway accepting modules on network and address, such as
+// "unix" and a socket path or "tcp" and "127.0.0.1:0".
+func Listen(network, address string) (*Gateway, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	gateway := New()
+	go gateway.Serve(listener)
+	return gateway, nil
+}
+
+// Serve accepts modules on listener until it is closed.
+func (gateway *Gateway) Serve(listener net.Listener) error {
+	gateway.Lock()
+	gateway.listener = listener
+	gateway.Unlock()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go gateway.serveConn(conn)
+	}
+}
+
+func (gateway *Gateway) Addr() net.Addr {
+	gateway.Lock()
+	defer gateway.Unlock()
+	if gateway.listener == nil {
+		return nil
+	}
+	return gateway.listener.Addr()
+}
+
+func (gateway *Gateway) Close() error {
+	gateway.Lock()
+	defer gateway.Unlock()
+	if gateway.listener == nil {
+		return nil
+	}
+	return gateway.listener.Close()
+}
+
+func (gateway *Gateway) serveConn(conn net.Conn) {
+	client := &session{
+		conn:      conn,
+		functions: make(map[string]bool),
+		hooks:     make(map[string]bool),
+	}
+	client.ready = sync.NewCond(&client.Mutex)
+	go client.write()
+	defer gateway.disconnect(client)
+
+	reader := bufio.NewReaderSize(conn, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		gateway.handle(client, gateway.protocol.Decode(line))
+	}
+}
+
+func (gateway *Gateway) disconnect(client *session) {
+	_ = client.conn.Close()
+	client.Lock()
+	client.closed = true
+	client.ready.Broadcast()
+	client.Unlock()
+
+	gateway.Lock()
+	defer gateway.Unlock()
+	if gateway.modules[client.moduleId] == client {
+		delete(gateway.modules, client.moduleId)
+	}
+	for hook := range client.hooks {
+		delete(gateway.hooks[hook], client)
+	}
+	for requestId, caller := range gateway.pending {
+		if caller == client {
+			delete(gateway.pending, requestId)
+		}
+	}
+	gateway.updateActivation()
+}
+
+func (gateway *Gateway) send(client *session, message models.BaseMessage) {
+	data, err := gateway.protocol.Encode(message)
+	if err != nil {
+		return
+	}
+	client.Lock()
+	client.outbox = append(client.outbox, data)
+	client.ready.Signal()
+	client.Unlock()
+}
+
+// write sends queued messages in order until the session is closed. A module
+// that reads slowly only holds up its own messages.
+func (client *session) write() {
+	client.Lock()
+	defer client.Unlock()
+	for {
+		for len(client.outbox) == 0 && !client.closed {
+			client.ready.Wait()
+		}
+		if client.closed {
+			return
+		}
+		outbox := client.outbox
+		client.outbox = nil
+		client.Unlock()
+		for _, data := range outbox {
+			if _, err := client.conn.Write(data); err != nil {
+				// The read loop notices the closed connection and
+				// disconnects the module.
+				_ = client.conn.Close()
+				break
+			}
+		}
+		client.Lock()
+	}
+}
+
+func (gateway *Gateway) handle(client *session, message models.BaseMessage) {
+	gateway.Lock()
+	defer gateway.Unlock()
+
+	if _, ok := message.(models.RegisterModuleRequest); !ok && client.moduleId == "" {
+		gateway.send(client, models.ErrorMessage{RequestId: message.GetRequestId(), Error: error_codes.UnregisteredModule})
+		return
+	}
+
+	switch request := message.(type) {
+	case models.RegisterModuleRequest:
+		gateway.registerModule(client, request)
+	case models.DeclareFunctionRequest:
+		client.functions[request.Function] = true
+		gateway.send(client, models.DeclareFunctionResponse{
+			RequestId: request.RequestId,
+			Function:  request.Function,
+		})
+	case models.FunctionCallRequest:
+		gateway.callFunction(client, request)
+	case models.FunctionCallResponse:
+		caller := gateway.pending[request.RequestId]
+		delete(gateway.pending, request.RequestId)
+		if caller != nil {
+			gateway.send(caller, request)
+		}
+	case models.RegisterHookRequest:
+		if gateway.hooks[request.Hook] == nil {
+			gateway.hooks[request.Hook] = make(map[*session]bool)
+		}
+		gateway.hooks[request.Hook][client] = true
+		client.hooks[request.Hook] = true
+		gateway.send(client, models.RegisterHookResponse{RequestId: request.RequestId})
+	case models.UnregisterHookRequest:
+		delete(gateway.hooks[request.Hook], client)
+		delete(client.hooks, request.Hook)
+		gateway.send(client, models.UnregisterHookResponse{RequestId: request.RequestId})
+	case models.UndeclareFunctionRequest:
+		delete(client.functions, request.Function)
+		gateway.send(client, models.UndeclareFunctionResponse{
+			RequestId: request.RequestId,
+			Function:  request.Function,
+		})
+	case models.TriggerHookRequest:
+		gateway.triggerHook(client, request)
+	case models.UnknownMessage:
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.MalformedRequest})
+	default:
+		gateway.send(client, models.ErrorMessage{RequestId: message.GetRequestId(), Error: error_codes.UnknownRequest})
+	}
+}
+
+func (gateway *Gateway) registerModule(client *session, request models.RegisterModuleRequest) {
+	if client.moduleId != "" || gateway.modules[request.ModuleId] != nil {
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.DuplicateModule})
+		return
+	}
+	if request.ModuleId == "" || strings.ContainsAny(request.ModuleId, ". ") {
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.InvalidModuleId})
+		return
+	}
+
+	client.moduleId = request.ModuleId
+	client.version = request.Version
+	client.dependencies = request.Dependencies
+	gateway.modules[request.ModuleId] = client
+	gateway.send(client, models.RegisterModuleResponse{RequestId: request.RequestId})
+	gateway.updateActivation()
+}
+
+// updateActivation sends juno.activated to modules whose dependencies are all
+// registered with a version in the required range, and juno.deactivated to
+// those that lost one.
+func (gateway *Gateway) updateActivation() {
+	for _, module := range gateway.modules {
+		satisfied := true
+		for dependency, versionRange := range module.dependencies {
+			if !gateway.satisfies(dependency, versionRange) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied == module.activated {
+			continue
+		}
+		module.activated = satisfied
+		hook := "juno.deactivated"
+		if satisfied {
+			hook = "juno.activated"
+		}
+		gateway.send(module, models.TriggerHookResponse{
+			RequestId: protocol.GenerateRequestId("juno"),
+			Hook:      hook,
+		})
+	}
+}
+
+func (gateway *Gateway) satisfies(dependency, versionRange string) bool {
+	target := gateway.modules[dependency]
+	if target == nil {
+		return false
+	}
+	required, err := semver.ParseRange(versionRange)
+	if err != nil {
+		return false
+	}
+	version, err := semver.Parse(target.version)
+	return err == nil && required.Matches(version)
+}
+
+func (gateway *Gateway) callFunction(client *session, request models.FunctionCallRequest) {
+	separator := strings.Index(request.Function, ".")
+	if separator < 0 {
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.UnknownModule})
+		return
+	}
+	target := gateway.modules[request.Function[:separator]]
+	if target == nil {
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.UnknownModule})
+		return
+	}
+	function := request.Function[separator+1:]
+	if !target.functions[function] {
+		gateway.send(client, models.ErrorMessage{RequestId: request.RequestId, Error: error_codes.UnknownFunction})
+		return
+	}
+
+	gateway.pending[request.RequestId] = client
+	request.Function = function
+	gateway.send(target, request)
+}
+
+// listeners returns the sessions listening to hook, either by name or through
+// a pattern, each once.
+func (gateway *Gateway) listeners(hook string) map[*session]bool {
+	listeners := make(map[*session]bool)
+	for registered, sessions := range gateway.hooks {
+		if !hook_pattern.Match(registered, hook) {
+			continue
+		}
+		for listener := range sessions {
+			listeners[listener] = true
+		}
+	}
+	return listeners
+}
+
+func (gateway *Gateway) triggerHook(client *session, request models.TriggerHookRequest) {
+	hook := client.moduleId + "." + request.Hook
+	for listener := range gateway.listeners(hook) {
+		gateway.send(listener, models.TriggerHookResponse{
+			RequestId: request.RequestId,
+			Hook:      hook,
+			Data:      request.Data,
+			Meta:      request.Meta,
+		})
+	}
+	gateway.send(client, models.TriggerHookResponse{RequestId: request.RequestId})
+}
@@ -0,0 +1,71 @@
+package gateway_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/gateway"
+)
+
+// sent fails the test when sending a request failed.
+func sent(t *testing.T) func(chan interface{}, error) chan interface{} {
+	return func(channel chan interface{}, err error) chan interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return channel
+	}
+}
+
+func await(t *testing.T, channel chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case value := <-channel:
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the gateway")
+		return nil
+	}
+}
+
+func TestSlowModuleDoesNotStallGateway(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go gateway.New().Serve(listener)
+	address := listener.Addr().String()
+
+	// A module that listens to a hook but never reads what it is sent.
+	slow, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	_, err = slow.Write([]byte(`{"requestId":"s-1","type":1,"moduleId":"slow","version":"1.0.0","dependencies":{}}` + "\n" +
+		`{"requestId":"s-2","type":5,"hook":"fast.tick"}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	module := juno.Default(address)
+	await(t, sent(t)(module.Initialize("fast", "1.0.0", nil)))
+	defer module.Close()
+	await(t, sent(t)(module.DeclareFunction("echo", func(args map[string]interface{}) interface{} {
+		return args["value"]
+	})))
+
+	// Far more than the socket buffers of the slow module can hold.
+	payload := strings.Repeat("x", 64*1024)
+	for i := 0; i < 200; i++ {
+		await(t, sent(t)(module.TriggerHook("tick", payload)))
+	}
+	if result := await(t, sent(t)(module.CallFunction("fast.echo", map[string]interface{}{"value": "ok"}))); result != "ok" {
+		t.Fatalf("got %v, want ok", result)
+	}
+}
@@ -0,0 +1,66 @@
+package juno_go
+
+import (
+	"context"
+	"sync"
+)
+
+// QueryHandler answers a hook broadcast by Gather.
+type QueryHandler func(ctx context.Context, event HookEvent) (interface{}, error)
+
+// GatherResult holds what one module answered to Gather: a result for each
+// of its query handlers that succeeded, and the failures of the others.
+type GatherResult struct {
+	Module   string
+	Results  []interface{}
+	Failures []ListenerFailure
+}
+
+type queryResultsKey struct{}
+
+// queryResults collects the answers of a module's query handlers to one hook
+// delivery.
+type queryResults struct {
+	sync.Mutex
+	values []interface{}
+}
+
+// RegisterQueryHandler listens to hook, which may be a pattern, and sends
+// the handler's result back to modules calling Gather. When the hook is
+// triggered normally, the result is discarded.
+func (module *JunoModule) RegisterQueryHandler(hook string, handler QueryHandler) (chan interface{}, *Subscription, error) {
+	return module.RegisterHookPattern(hook, func(ctx context.Context, event HookEvent) error {
+		value, err := handler(ctx, event)
+		if err != nil {
+			return err
+		}
+		if results, ok := ctx.Value(queryResultsKey{}).(*queryResults); ok {
+			results.Lock()
+			results.values = append(results.values, value)
+			results.Unlock()
+		}
+		return nil
+	})
+}
+
+// Gather broadcasts a query on hook and collects the answers of the query
+// handlers of every receiving module until ctx is done, or for
+// DefaultAcknowledgementWindow when ctx has no deadline. Modules that
+// received the hook without answering or failing are left out. Answers come
+// back like receipts, so gathering needs a gateway that forwards hook
+// metadata, which juno doesn't; see DeliveryReport.
+func (module *JunoModule) Gather(ctx context.Context, hook string, data interface{}, opts ...CallOption) ([]GatherResult, error) {
+	report, err := module.TriggerHookAcknowledged(ctx, hook, data, opts...)
+	results := []GatherResult{}
+	for _, receipt := range report.Receipts {
+		if len(receipt.Results) == 0 && len(receipt.Failures) == 0 {
+			continue
+		}
+		results = append(results, GatherResult{
+			Module:   receipt.Module,
+			Results:  receipt.Results,
+			Failures: receipt.Failures,
+		})
+	}
+	return results, err
+}
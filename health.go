@@ -0,0 +1,154 @@
+package juno_go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	healthFunction = "health"
+
+	HealthOk      = "ok"
+	HealthFailing = "failing"
+)
+
+// DefaultHealthCheckTimeout bounds every health check that runs without a
+// deadline of its own.
+var DefaultHealthCheckTimeout = 5 * time.Second
+
+type HealthCheck func(ctx context.Context) error
+
+type HealthListType struct {
+	sync.RWMutex
+	m map[string]HealthCheck
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReport is returned by the health function. Status is ok when every
+// check passed. Activated tells whether the gateway activated the module,
+// which happens once all its dependencies are registered.
+type HealthReport struct {
+	Status    string                 `json:"status"`
+	Module    string                 `json:"module"`
+	Version   string                 `json:"version"`
+	Activated bool                   `json:"activated"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// AddHealthCheck registers a check, such as a database ping, reported under
+// name by the health function and HealthHandler. Adding a check under an
+// existing name replaces it.
+func (module *JunoModule) AddHealthCheck(name string, check HealthCheck) {
+	module.healthChecks.Lock()
+	module.healthChecks.m[name] = check
+	module.healthChecks.Unlock()
+}
+
+func (module *JunoModule) Ready() bool {
+	module.registered.RLock()
+	defer module.registered.RUnlock()
+	return module.registered.value
+}
+
+// Health runs all checks concurrently and reports their results.
+func (module *JunoModule) Health(ctx context.Context) HealthReport {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+		defer cancel()
+	}
+
+	report := HealthReport{
+		Status:    HealthOk,
+		Module:    module.moduleId,
+		Version:   module.version,
+		Activated: module.Ready(),
+		Checks:    map[string]CheckResult{},
+	}
+
+	module.healthChecks.RLock()
+	checks := make(map[string]HealthCheck, len(module.healthChecks.m))
+	for name, check := range module.healthChecks.m {
+		checks[name] = check
+	}
+	module.healthChecks.RUnlock()
+
+	var lock sync.Mutex
+	var done sync.WaitGroup
+	for name, check := range checks {
+		done.Add(1)
+		go func(name string, check HealthCheck) {
+			defer done.Done()
+			result := runCheck(ctx, check)
+			lock.Lock()
+			report.Checks[name] = result
+			if result.Status != HealthOk {
+				report.Status = HealthFailing
+			}
+			lock.Unlock()
+		}(name, check)
+	}
+	done.Wait()
+	return report
+}
+
+func runCheck(ctx context.Context, check HealthCheck) CheckResult {
+	startedAt := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckResult{Status: HealthOk, Duration: time.Since(startedAt).String()}
+	if err != nil {
+		result.Status = HealthFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (module *JunoModule) serveHealth(ctx context.Context, args map[string]interface{}) interface{} {
+	return module.Health(ctx)
+}
+
+// HealthHandler serves /healthz, which runs the health checks, and /readyz,
+// which reports whether the gateway activated the module. Both answer 200
+// when healthy and 503 otherwise.
+func (module *JunoModule) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/healthz"):
+			report := module.Health(r.Context())
+			status := http.StatusOK
+			if report.Status != HealthOk {
+				status = http.StatusServiceUnavailable
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(report)
+		case strings.HasSuffix(r.URL.Path, "/readyz"):
+			if !module.Ready() {
+				http.Error(w, "not activated", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
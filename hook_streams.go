@@ -0,0 +1,141 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// OverflowPolicy decides what Subscribe does with a delivery when the
+// subscriber's buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the subscriber, holding up the hook's other
+	// listeners in the meantime.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered event to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the new event.
+	OverflowDropNewest
+)
+
+type HookStreamListType struct {
+	sync.Mutex
+	m map[*hookStream]bool
+}
+
+type hookStream struct {
+	sync.Mutex
+	events    chan HookEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	closed    bool
+	overflow  OverflowPolicy
+}
+
+// Subscribe delivers hook, which may be a pattern, on a channel buffering
+// up to bufferSize events, which must be at least 1. It waits for the gateway to accept the
+// registration and returns its error otherwise. The channel is closed when
+// the subscription is unsubscribed or the module is closed.
+func (module *JunoModule) Subscribe(hook string, bufferSize int, overflow OverflowPolicy) (<-chan HookEvent, *Subscription, error) {
+	if bufferSize < 1 {
+		return nil, nil, errors.New("subscription buffer size must be at least 1")
+	}
+	stream := &hookStream{
+		events:   make(chan HookEvent, bufferSize),
+		done:     make(chan struct{}),
+		overflow: overflow,
+	}
+	module.hookStreams.Lock()
+	module.hookStreams.m[stream] = true
+	module.hookStreams.Unlock()
+
+	channel, subscription, err := module.RegisterHookPattern(hook, func(ctx context.Context, event HookEvent) error {
+		stream.push(event)
+		return nil
+	})
+	if err != nil {
+		module.removeHookStream(stream)
+		return nil, nil, err
+	}
+	subscription.onUnsubscribe = func() {
+		module.removeHookStream(stream)
+	}
+
+	select {
+	case result := <-channel:
+		if err, ok := result.(error); ok {
+			_, _ = subscription.Unsubscribe()
+			return nil, nil, err
+		}
+	case <-stream.done:
+		// The module was closed before the gateway answered.
+		return nil, nil, errors.New("module closed before the subscription was registered")
+	}
+	return stream.events, subscription, nil
+}
+
+func (module *JunoModule) removeHookStream(stream *hookStream) {
+	module.hookStreams.Lock()
+	delete(module.hookStreams.m, stream)
+	module.hookStreams.Unlock()
+	stream.close()
+}
+
+func (module *JunoModule) closeHookStreams() {
+	module.hookStreams.Lock()
+	streams := module.hookStreams.m
+	module.hookStreams.m = make(map[*hookStream]bool)
+	module.hookStreams.Unlock()
+	for stream := range streams {
+		stream.close()
+	}
+}
+
+func (stream *hookStream) push(event HookEvent) {
+	stream.Lock()
+	defer stream.Unlock()
+	if stream.closed {
+		return
+	}
+	switch stream.overflow {
+	case OverflowDropNewest:
+		select {
+		case stream.events <- event:
+		default:
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case stream.events <- event:
+				return
+			case <-stream.done:
+				return
+			default:
+			}
+			select {
+			case <-stream.events:
+			default:
+			}
+		}
+	default:
+		select {
+		case stream.events <- event:
+		case <-stream.done:
+		}
+	}
+}
+
+func (stream *hookStream) close() {
+	// Closing done first releases a blocked push, which holds the lock.
+	stream.closeOnce.Do(func() {
+		close(stream.done)
+	})
+	stream.Lock()
+	defer stream.Unlock()
+	if !stream.closed {
+		stream.closed = true
+		close(stream.events)
+	}
+}
@@ -0,0 +1,108 @@
+package juno_go
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	address := startGateway(t)
+	listener := startModule(t, address, "listener")
+	trigger := startModule(t, address, "trigger")
+
+	events, subscription, err := listener.Subscribe("trigger.*", 4, OverflowDropOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	await(t, mustSend(t)(trigger.TriggerHook("created", 1)))
+	select {
+	case event := <-events:
+		if event.Hook != "trigger.created" || event.Data != 1.0 {
+			t.Fatalf("got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event wasn't delivered")
+	}
+
+	await(t, mustSend(t)(subscription.Unsubscribe()))
+	select {
+	case _, open := <-events:
+		if open {
+			t.Fatal("got an event after unsubscribing")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel wasn't closed on unsubscribe")
+	}
+}
+
+func TestSubscribeRejectsEmptyBuffer(t *testing.T) {
+	address := startGateway(t)
+	listener := startModule(t, address, "listener")
+	for _, size := range []int{0, -1} {
+		if _, _, err := listener.Subscribe("trigger.created", size, OverflowDropOldest); err == nil {
+			t.Errorf("buffer size %d was accepted", size)
+		}
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	event := func(data int) HookEvent {
+		return HookEvent{Hook: "a.b", Data: data}
+	}
+	tests := []struct {
+		overflow OverflowPolicy
+		want     []interface{}
+	}{
+		{OverflowDropNewest, []interface{}{1, 2}},
+		{OverflowDropOldest, []interface{}{2, 3}},
+	}
+	for _, test := range tests {
+		stream := &hookStream{events: make(chan HookEvent, 2), done: make(chan struct{}), overflow: test.overflow}
+		for i := 1; i <= 3; i++ {
+			stream.push(event(i))
+		}
+		stream.close()
+		got := []interface{}{}
+		for delivered := range stream.events {
+			got = append(got, delivered.Data)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("policy %d: got %v, want %v", test.overflow, got, test.want)
+		}
+	}
+
+	// OverflowBlock waits for the reader, and closing releases it.
+	stream := &hookStream{events: make(chan HookEvent, 1), done: make(chan struct{}), overflow: OverflowBlock}
+	stream.push(event(1))
+	pushed := make(chan struct{})
+	go func() {
+		stream.push(event(2))
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("push didn't wait for the reader")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if delivered := <-stream.events; delivered.Data != 1 {
+		t.Fatalf("got %v, want 1", delivered.Data)
+	}
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("push wasn't released by the reader")
+	}
+
+	go stream.push(event(3))
+	closed := make(chan struct{})
+	go func() {
+		stream.close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close waited for a blocked push")
+	}
+}
@@ -0,0 +1,121 @@
+package juno_go
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/bytesonus/juno-go/protocol"
+	"github.com/bytesonus/juno-go/utils/hook_pattern"
+)
+
+// HookEvent is a hook delivery as seen by pattern listeners, which need to
+// know which hook fired.
+type HookEvent struct {
+	Hook string
+	Data interface{}
+	Meta map[string]string
+}
+
+type HookEventHandler func(ctx context.Context, event HookEvent) error
+
+// RegisterHookPattern listens to every hook matching pattern, such as
+// "orders.*", "*.created" or "orders.**" for all hooks of a module (see the
+// hook_pattern package). Plain hook names work with any gateway, but
+// patterns are matched by the gateway and require one that supports them,
+// such as the gateway package. juno matches hook names exactly, so it takes
+// a pattern for a hook name that never fires and the listener is never
+// called.
+func (module *JunoModule) RegisterHookPattern(pattern string, cb HookEventHandler) (chan interface{}, *Subscription, error) {
+	module.hookListeners.requests.Lock()
+	defer module.hookListeners.requests.Unlock()
+	module.hookListeners.Lock()
+	module.hookListeners.nextId++
+	listener := hookListener{id: module.hookListeners.nextId, pattern: pattern, handler: cb}
+	module.hookListeners.m[pattern] = append(module.hookListeners.m[pattern], listener)
+	module.hookListeners.Unlock()
+	subscription := &Subscription{module: module, hook: pattern, id: listener.id}
+
+	channel, err := module.sendRequest(
+		protocol.RegisterHook(module.protocol, pattern),
+	)
+	if err != nil {
+		module.hookListeners.Lock()
+		module.removeListener(pattern, listener.id)
+		module.hookListeners.Unlock()
+		return nil, nil, err
+	}
+	return channel, subscription, nil
+}
+
+// listenersFor returns the listeners registered for hook itself and for every
+// pattern matching it.
+func (module *JunoModule) listenersFor(hook string) []hookListener {
+	module.hookListeners.RLock()
+	defer module.hookListeners.RUnlock()
+	listeners := append([]hookListener{}, module.hookListeners.m[hook]...)
+	for pattern, matching := range module.hookListeners.m {
+		if hook_pattern.IsPattern(pattern) && hook_pattern.Match(pattern, hook) {
+			listeners = append(listeners, matching...)
+		}
+	}
+	return listeners
+}
+
+// Once calls cb for the first delivery of hook only, then unsubscribes.
+// Unsubscribing before the hook fires cancels it.
+func (module *JunoModule) Once(hook string, cb func(interface{})) (chan interface{}, *Subscription, error) {
+	var fired int32
+	registered := make(chan *Subscription, 1)
+	channel, subscription, err := module.RegisterHookPattern(hook, func(ctx context.Context, event HookEvent) error {
+		if !atomic.CompareAndSwapInt32(&fired, 0, 1) {
+			return nil
+		}
+		// The delivery can race with RegisterHookPattern returning.
+		go func() {
+			if subscription := <-registered; subscription != nil {
+				_, _ = subscription.Unsubscribe()
+			}
+		}()
+		cb(event.Data)
+		return nil
+	})
+	registered <- subscription
+	if err != nil {
+		return nil, nil, err
+	}
+	return channel, subscription, nil
+}
+
+// WaitForHook blocks until hook, which may be a pattern, is delivered with
+// an event satisfying predicate, or ctx is done. A nil predicate accepts any
+// event.
+func (module *JunoModule) WaitForHook(ctx context.Context, hook string, predicate func(HookEvent) bool) (HookEvent, error) {
+	events := make(chan HookEvent, 1)
+	var matched int32
+	channel, subscription, err := module.RegisterHookPattern(hook, func(ctx context.Context, event HookEvent) error {
+		if predicate != nil && !predicate(event) {
+			return nil
+		}
+		if atomic.CompareAndSwapInt32(&matched, 0, 1) {
+			events <- event
+		}
+		return nil
+	})
+	if err != nil {
+		return HookEvent{}, err
+	}
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case result := <-channel:
+			if err, ok := result.(error); ok {
+				return HookEvent{}, err
+			}
+		case event := <-events:
+			return event, nil
+		case <-ctx.Done():
+			return HookEvent{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package juno_go
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bytesonus/juno-go/models"
+	"github.com/bytesonus/juno-go/tracing"
+)
+
+// Invoker sends an outgoing message and returns the channel its response is
+// delivered on.
+type Invoker func(message models.BaseMessage) (chan interface{}, error)
+
+// ClientInterceptor wraps outgoing CallFunction and TriggerHook requests. It
+// may inspect or replace the message before handing it to next, or return
+// without calling next to short-circuit the request.
+type ClientInterceptor func(message models.BaseMessage, next Invoker) (chan interface{}, error)
+
+// Handler processes an incoming FunctionCallRequest or TriggerHookResponse
+// and returns the value sent back to the caller, if any.
+type Handler func(message models.BaseMessage) interface{}
+
+// ServerInterceptor wraps the execution of declared functions and hook
+// listeners.
+type ServerInterceptor func(message models.BaseMessage, next Handler) interface{}
+
+type InterceptorListType struct {
+	sync.RWMutex
+	client []ClientInterceptor
+	server []ServerInterceptor
+}
+
+// UseClientInterceptors appends interceptors to the outgoing chain. The first
+// interceptor registered is the outermost one.
+func (module *JunoModule) UseClientInterceptors(interceptors ...ClientInterceptor) {
+	module.interceptors.Lock()
+	module.interceptors.client = append(module.interceptors.client, interceptors...)
+	module.interceptors.Unlock()
+}
+
+// UseServerInterceptors appends interceptors to the incoming chain. The first
+// interceptor registered is the outermost one.
+func (module *JunoModule) UseServerInterceptors(interceptors ...ServerInterceptor) {
+	module.interceptors.Lock()
+	module.interceptors.server = append(module.interceptors.server, interceptors...)
+	module.interceptors.Unlock()
+}
+
+func (module *JunoModule) invoke(ctx context.Context, message models.BaseMessage, span tracing.Span) (chan interface{}, error) {
+	module.interceptors.RLock()
+	interceptors := module.interceptors.client
+	module.interceptors.RUnlock()
+
+	sent := false
+	next := Invoker(func(message models.BaseMessage) (chan interface{}, error) {
+		sent = true
+		return module.sendTracedRequest(ctx, message, span)
+	})
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(message models.BaseMessage) (chan interface{}, error) {
+			return interceptor(message, inner)
+		}
+	}
+	channel, err := next(message)
+	if !sent || err != nil {
+		// The request never made it to the pending list, so nothing else
+		// will end its span.
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+	return channel, err
+}
+
+func (module *JunoModule) handle(message models.BaseMessage, handler Handler) interface{} {
+	module.interceptors.RLock()
+	interceptors := module.interceptors.server
+	module.interceptors.RUnlock()
+
+	next := handler
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(message models.BaseMessage) interface{} {
+			return interceptor(message, inner)
+		}
+	}
+	return next(message)
+}
@@ -1,41 +1,86 @@
 package juno_go
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"regexp"
 	"sync"
+	"time"
 
 	"github.com/bytesonus/juno-go/connection"
+	"github.com/bytesonus/juno-go/logging"
 	"github.com/bytesonus/juno-go/models"
 	"github.com/bytesonus/juno-go/protocol"
+	"github.com/bytesonus/juno-go/schema"
+	"github.com/bytesonus/juno-go/tracing"
+	"github.com/bytesonus/juno-go/utils/error_codes"
 	"github.com/bytesonus/juno-go/utils/request_types"
+	"github.com/bytesonus/juno-go/utils/semver"
 )
 
 type RequestListType struct {
 	sync.RWMutex
-	m map[string]chan interface{}
+	m map[string]*pendingRequest
+}
+type pendingRequest struct {
+	// ctx is the caller's context, which bounds work done on its behalf
+	// before the request resolves, such as fetching chunks.
+	ctx     context.Context
+	channel chan interface{}
+	// done is closed once the request resolved.
+	done     chan struct{}
+	function string
+	sentAt   time.Time
+	span     tracing.Span
+	expiry   *time.Timer
 }
 type FunctionListType struct {
 	sync.RWMutex
-	m map[string]func(map[string]interface{}) interface{}
+	m       map[string]FunctionHandler
+	schemas map[string]schema.Function
+	types   map[string]schema.Type
 }
 type HookListType struct {
 	sync.RWMutex
-	m map[string][]func(interface{})
+	m      map[string][]hookListener
+	nextId uint64
+	// requests keeps register and unregister requests in the order the
+	// listeners changed in, without holding up deliveries while sending.
+	requests sync.Mutex
 }
 type MutexBool struct {
 	sync.RWMutex
 	value bool
 }
 
+type FunctionHandler func(ctx context.Context, args map[string]interface{}) interface{}
+type HookHandler func(ctx context.Context, data interface{}) error
+
 type JunoModule struct {
 	connection    connection.BaseConnection
 	protocol      protocol.BaseProtocol
+	moduleId      string
+	version       string
 	requests      RequestListType
 	functions     FunctionListType
 	hookListeners HookListType
-	messageBuffer []byte
+	hookStreams   HookStreamListType
+	messageBuffer [][]byte
 	registered    MutexBool
+	chunks        ChunkListType
+	chunkSize     int
+	interceptors  InterceptorListType
+	healthChecks  HealthListType
+	acks          AckListType
+	streams       StreamListType
+	uploads       UploadListType
+	metrics       MetricsType
+	tracer        tracing.Tracer
+	logger        logging.Logger
+	debug         int32
 }
 
 func Default(connectionPath string) JunoModule {
@@ -59,33 +104,110 @@ func NewJunoModule(protocol protocol.BaseProtocol, connection connection.BaseCon
 		connection: connection,
 		protocol:   protocol,
 		requests: RequestListType{
-			m: make(map[string]chan interface{}),
+			m: make(map[string]*pendingRequest),
 		},
 		functions: FunctionListType{
-			m: make(map[string]func(map[string]interface{}) interface{}),
+			m:       make(map[string]FunctionHandler),
+			schemas: make(map[string]schema.Function),
+			types:   make(map[string]schema.Type),
 		},
 		hookListeners: HookListType{
-			m: make(map[string][]func(interface{})),
+			m: make(map[string][]hookListener),
+		},
+		hookStreams: HookStreamListType{
+			m: make(map[*hookStream]bool),
 		},
-		messageBuffer: []byte{},
+		messageBuffer: [][]byte{},
 		registered: MutexBool{
 			value: false,
 		},
+		chunks: ChunkListType{
+			m: make(map[string][][]byte),
+		},
+		healthChecks: HealthListType{
+			m: make(map[string]HealthCheck),
+		},
+		acks: AckListType{
+			m: make(map[string]*ackCollector),
+		},
+		streams: StreamListType{
+			m:     make(map[string]*StreamWriter),
+			pulls: make(map[*FunctionStream]bool),
+		},
+		uploads: UploadListType{
+			m: make(map[string]*upload),
+		},
+		metrics: newMetrics(),
+		tracer:  tracing.NewPropagatingTracer(),
+		logger:  logging.Nop(),
 	}
 }
 
 func (module *JunoModule) Initialize(moduleId, version string, dependencies map[string]string) (chan interface{}, error) {
+	if err := validateVersions(version, dependencies); err != nil {
+		return nil, err
+	}
+
+	module.moduleId = moduleId
+	module.version = version
+
+	module.connection.SetOnDataHandler(module.onDataHandler)
+	if reporting, ok := module.connection.(connection.ErrorReportingConnection); ok {
+		reporting.SetOnErrorHandler(module.onFrameError)
+	}
 	err := module.connection.SetupConnection()
 	if err != nil {
 		return nil, err
 	}
-	module.connection.SetOnDataHandler(module.onDataHandler)
 
 	request := protocol.Initialize(module.protocol, moduleId, version, dependencies)
-	return module.sendRequest(request)
+	channel, err := module.sendRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = module.DeclareFunction(describeFunction, module.describe)
+	if err != nil {
+		return nil, err
+	}
+	_, err = module.DeclareFunctionContext(healthFunction, module.serveHealth)
+	if err != nil {
+		return nil, err
+	}
+	_, err = module.DeclareFunction(hookReplyFunction, module.serveHookReply)
+	if err != nil {
+		return nil, err
+	}
+	if module.chunkSize > 0 {
+		_, err = module.DeclareFunction(chunkFunction, module.serveChunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return channel, nil
+}
+
+// validateVersions checks the module version and dependency ranges before
+// registering, since the gateway would otherwise never activate the module.
+func validateVersions(version string, dependencies map[string]string) error {
+	if _, err := semver.Parse(version); err != nil {
+		return err
+	}
+	for dependency, versionRange := range dependencies {
+		if _, err := semver.ParseRange(versionRange); err != nil {
+			return fmt.Errorf("dependency %s: %w", dependency, err)
+		}
+	}
+	return nil
 }
 
 func (module *JunoModule) DeclareFunction(fnName string, fn func(map[string]interface{}) interface{}) (chan interface{}, error) {
+	return module.DeclareFunctionContext(fnName, func(ctx context.Context, args map[string]interface{}) interface{} {
+		return fn(args)
+	})
+}
+
+func (module *JunoModule) DeclareFunctionContext(fnName string, fn FunctionHandler) (chan interface{}, error) {
 	module.functions.Lock()
 	module.functions.m[fnName] = fn
 	module.functions.Unlock()
@@ -94,74 +216,247 @@ func (module *JunoModule) DeclareFunction(fnName string, fn func(map[string]inte
 	)
 }
 
-func (module *JunoModule) CallFunction(fnName string, args map[string]interface{}) (chan interface{}, error) {
-	return module.sendRequest(
-		protocol.CallFunction(module.protocol, fnName, args),
-	)
+func (module *JunoModule) CallFunction(fnName string, args map[string]interface{}, opts ...CallOption) (chan interface{}, error) {
+	return module.CallFunctionContext(context.Background(), fnName, args, opts...)
 }
 
-func (module *JunoModule) RegisterHook(hook string, cb func(interface{})) (chan interface{}, error) {
-	module.hookListeners.Lock()
-	defer module.hookListeners.Unlock()
-	if module.hookListeners.m[hook] != nil {
-		module.hookListeners.m[hook] = append(module.hookListeners.m[hook], cb)
-	} else {
-		module.hookListeners.m[hook] = []func(interface{}){cb}
+func (module *JunoModule) CallFunctionContext(ctx context.Context, fnName string, args map[string]interface{}, opts ...CallOption) (chan interface{}, error) {
+	options := newCallOptions(opts)
+	if deadline, ok := ctx.Deadline(); ok {
+		WithDeadline(deadline)(options)
 	}
-	return module.sendRequest(
-		protocol.RegisterHook(module.protocol, hook),
-	)
+	ctx, span := module.tracer.Start(ctx, fnName, tracing.SpanKindClient)
+	request := protocol.CallFunction(module.protocol, fnName, args).(models.FunctionCallRequest)
+	request.Meta = options.meta
+	tracing.Inject(ctx, request.Meta)
+	if !options.deadline.IsZero() {
+		request.Deadline = options.deadline.UnixNano() / int64(time.Millisecond)
+	}
+
+	return module.invoke(ctx, request, span)
 }
 
-func (module *JunoModule) TriggerHook(hook string, data interface{}) (chan interface{}, error) {
-	return module.sendRequest(
-		protocol.TriggerHook(module.protocol, hook, data),
-	)
+func (module *JunoModule) RegisterHook(hook string, cb func(interface{})) (chan interface{}, *Subscription, error) {
+	return module.RegisterHookContext(hook, func(ctx context.Context, data interface{}) error {
+		cb(data)
+		return nil
+	})
+}
+
+func (module *JunoModule) RegisterHookContext(hook string, cb HookHandler) (chan interface{}, *Subscription, error) {
+	return module.RegisterHookPattern(hook, func(ctx context.Context, event HookEvent) error {
+		return cb(ctx, event.Data)
+	})
+}
+
+func (module *JunoModule) TriggerHook(hook string, data interface{}, opts ...CallOption) (chan interface{}, error) {
+	return module.TriggerHookContext(context.Background(), hook, data, opts...)
+}
+
+func (module *JunoModule) TriggerHookContext(ctx context.Context, hook string, data interface{}, opts ...CallOption) (chan interface{}, error) {
+	options := newCallOptions(opts)
+	ctx, span := module.tracer.Start(ctx, hook, tracing.SpanKindProducer)
+	request := protocol.TriggerHook(module.protocol, hook, data).(models.TriggerHookRequest)
+	request.Meta = options.meta
+	tracing.Inject(ctx, request.Meta)
+	return module.invoke(ctx, request, span)
+}
+
+// SetTracer replaces the tracer used to start spans for outgoing calls,
+// triggered hooks and their handlers. By default spans are only propagated.
+func (module *JunoModule) SetTracer(tracer tracing.Tracer) {
+	module.tracer = tracer
+}
+
+func (module *JunoModule) SetMessageSizeLimits(inbound, outbound int) error {
+	limited, ok := module.connection.(connection.SizeLimitedConnection)
+	if !ok {
+		return errors.New("connection doesn't support message size limits")
+	}
+	limited.SetMaxInboundSize(inbound)
+	limited.SetMaxOutboundSize(outbound)
+	return nil
+}
+
+var requestIdPattern = regexp.MustCompile(`"requestId"\s*:\s*"([^"\\]*)"`)
+
+// onFrameError fails the request an oversized frame answered, looking for its
+// id at the start and end of the frame. When the frame can't be attributed,
+// every pending request fails, since any of them may have been waiting for it.
+func (module *JunoModule) onFrameError(err error) {
+	var tooLarge *connection.FrameTooLargeError
+	if !errors.As(err, &tooLarge) {
+		module.logger.Error("connection failed to deliver a frame", "error", err)
+		return
+	}
+	ids := []string{}
+	for _, excerpt := range [][]byte{tooLarge.Head, tooLarge.Tail} {
+		for _, match := range requestIdPattern.FindAllSubmatch(excerpt, -1) {
+			ids = append(ids, string(match[1]))
+		}
+	}
+	if len(ids) == 0 {
+		module.requests.Lock()
+		for requestId := range module.requests.m {
+			ids = append(ids, requestId)
+		}
+		module.requests.Unlock()
+	}
+	for _, requestId := range ids {
+		module.resolveRequest(requestId, err)
+	}
+}
+
+// RecordTo writes every frame the module sends and receives to out, so the
+// session can be replayed with a connection.ReplayConnection. It must be
+// called before Initialize.
+func (module *JunoModule) RecordTo(out io.Writer) *connection.RecordingConnection {
+	recording := connection.NewRecordingConnection(module.connection, out)
+	recording.SetLogger(module.logger)
+	module.connection = recording
+	return recording
 }
 
 func (module *JunoModule) Close() error {
+	module.closeHookStreams()
+	module.closeStreams()
 	return module.connection.CloseConnection()
 }
 
 func (module *JunoModule) sendRequest(message models.BaseMessage) (chan interface{}, error) {
-	module.registered.RLock()
+	return module.sendTracedRequest(context.Background(), message, nil)
+}
+
+func (module *JunoModule) sendTracedRequest(ctx context.Context, message models.BaseMessage, span tracing.Span) (chan interface{}, error) {
+	pending := &pendingRequest{
+		ctx:     ctx,
+		channel: make(chan interface{}, 1),
+		done:    make(chan struct{}),
+		sentAt:  time.Now(),
+		span:    span,
+	}
+	switch request := message.(type) {
+	case models.FunctionCallRequest:
+		pending.function = request.Function
+	case models.TriggerHookRequest:
+		module.metrics.hookTriggered(request.Hook)
+	}
+	module.requests.Lock()
+	module.requests.m[message.GetRequestId()] = pending
+	module.requests.Unlock()
+
+	err := module.sendMessage(message)
+	if err != nil {
+		module.requests.Lock()
+		delete(module.requests.m, message.GetRequestId())
+		module.requests.Unlock()
+		return nil, err
+	}
+
+	// Interceptors may have replaced the message, so its own id and deadline
+	// are the ones to watch.
+	if request, ok := message.(models.FunctionCallRequest); ok && request.Deadline != 0 {
+		module.expireRequest(request.RequestId, time.Unix(0, request.Deadline*int64(time.Millisecond)))
+	}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				module.resolveRequest(message.GetRequestId(), ctx.Err())
+			case <-pending.done:
+			}
+		}()
+	}
+	return pending.channel, nil
+}
+
+func (module *JunoModule) sendMessage(message models.BaseMessage) error {
+	module.registered.Lock()
+	defer module.registered.Unlock()
 	if message.GetType() == request_types.RegisterModuleRequest && module.registered.value {
-		return nil, errors.New("module already registered")
+		return errors.New("module already registered")
 	}
 
 	encoded, err := module.protocol.Encode(message)
 	if err != nil {
-		return nil, err
+		module.metrics.encodeError()
+		module.logger.Error("failed to encode message", "type", request_types.Name(message.GetType()), "error", err)
+		return err
 	}
+	module.metrics.messageSent(message.GetType())
+	module.logMessage("out", message, len(encoded))
 	if module.registered.value || message.GetType() == request_types.RegisterModuleRequest {
-		err = module.connection.Send(encoded)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		module.messageBuffer = append(module.messageBuffer, encoded...)
+		return module.connection.Send(encoded)
 	}
-	module.registered.RUnlock()
+	module.messageBuffer = append(module.messageBuffer, encoded)
+	return nil
+}
 
+func (module *JunoModule) resolveRequest(requestId string, value interface{}) {
 	module.requests.Lock()
-	channel := make(chan interface{})
-	module.requests.m[message.GetRequestId()] = channel
+	pending := module.requests.m[requestId]
+	delete(module.requests.m, requestId)
 	module.requests.Unlock()
-	return channel, nil
+	if pending == nil {
+		return
+	}
+	close(pending.done)
+	if pending.expiry != nil {
+		pending.expiry.Stop()
+	}
+	if pending.function != "" {
+		module.metrics.observeCall(pending.function, time.Since(pending.sentAt))
+	}
+	if pending.span != nil {
+		if err, ok := value.(error); ok {
+			pending.span.RecordError(err)
+		}
+		pending.span.End()
+	}
+	pending.channel <- value
+}
+
+func (module *JunoModule) requestContext(requestId string) context.Context {
+	module.requests.Lock()
+	defer module.requests.Unlock()
+	if pending := module.requests.m[requestId]; pending != nil {
+		return pending.ctx
+	}
+	return context.Background()
+}
+
+// expireRequest resolves the request with context.DeadlineExceeded once
+// deadline passes, so that a response arriving later is discarded.
+func (module *JunoModule) expireRequest(requestId string, deadline time.Time) {
+	module.requests.Lock()
+	defer module.requests.Unlock()
+	pending := module.requests.m[requestId]
+	if pending == nil {
+		return
+	}
+	pending.expiry = time.AfterFunc(time.Until(deadline), func() {
+		module.resolveRequest(requestId, context.DeadlineExceeded)
+	})
 }
 
 func (module *JunoModule) onDataHandler(data []byte) {
 	response := module.protocol.Decode(data)
+	if _, ok := response.(models.UnknownMessage); ok {
+		module.metrics.decodeError()
+		module.logger.Warn("failed to decode message", "bytes", len(data))
+	}
+	module.metrics.messageReceived(response.GetType())
+	module.logMessage("in", response, len(data))
 	var value interface{}
 	switch response.GetType() {
-	case request_types.RegisterModuleRequest:
+	case request_types.RegisterModuleResponse:
 		{
 			value = true
 			break
 		}
 	case request_types.FunctionCallResponse:
 		{
-			value = response.(models.FunctionCallResponse).Data
+			value = module.decodeResponse(module.requestContext(response.GetRequestId()), response.(models.FunctionCallResponse).Data)
 			break
 		}
 	case request_types.DeclareFunctionResponse:
@@ -174,14 +469,48 @@ func (module *JunoModule) onDataHandler(data []byte) {
 			value = true
 			break
 		}
+	case request_types.UnregisterHookResponse:
+		{
+			value = true
+			break
+		}
+	case request_types.UndeclareFunctionResponse:
+		{
+			value = true
+			break
+		}
 	case request_types.TriggerHookResponse:
 		{
-			value = module.executeHookTriggered(response.(models.TriggerHookResponse))
+			request := response.(models.TriggerHookResponse)
+			if request.Hook != "" {
+				// Hooks triggered by other modules don't answer any of our
+				// requests, even when they share a request id with one.
+				module.executeHookTriggered(request)
+				return
+			}
+			value = true
 			break
 		}
 	case request_types.FunctionCallRequest:
 		{
-			value = module.executeFunctionCall(response.(models.FunctionCallRequest))
+			module.executeFunctionCall(response.(models.FunctionCallRequest))
+			return
+		}
+	case request_types.Error:
+		{
+			message, ok := response.(models.ErrorMessage)
+			if !ok {
+				value = false
+				break
+			}
+			if message.Error == error_codes.UnknownRequest {
+				// Expected for the optional requests juno doesn't implement,
+				// which sendOptionalRequest reports on.
+				module.logger.Debug("gateway doesn't know request", "requestId", message.RequestId)
+			} else {
+				module.logger.Warn("gateway returned an error", "requestId", message.RequestId, "error", message.Error)
+			}
+			value = &GatewayError{RequestId: message.RequestId, Code: message.Error}
 			break
 		}
 	default:
@@ -191,58 +520,130 @@ func (module *JunoModule) onDataHandler(data []byte) {
 		}
 	}
 
-	module.requests.Lock()
-	if module.requests.m[response.GetRequestId()] != nil {
-		module.requests.m[response.GetRequestId()] <- value
-		delete(module.requests.m, response.GetRequestId())
-	}
-	module.requests.Unlock()
+	module.resolveRequest(response.GetRequestId(), value)
 }
 
 func (module *JunoModule) executeFunctionCall(request models.FunctionCallRequest) bool {
 	module.functions.RLock()
-	defer module.functions.RUnlock()
-	if module.functions.m[request.Function] != nil {
-		res := module.functions.m[request.Function](request.Arguments)
-		if res.(chan interface{}) != nil {
-			res = <-res.(chan interface{})
-		}
-		channel, err := module.sendRequest(models.FunctionCallResponse{
+	fn := module.functions.m[request.Function]
+	module.functions.RUnlock()
+	if fn == nil {
+		// Function wasn't found in the module, possibly because it was
+		// undeclared and the gateway doesn't know about that.
+		module.logger.Warn("call to undeclared function", "function", request.Function, "requestId", request.RequestId)
+		err := module.sendMessage(models.FunctionCallResponse{
 			RequestId: request.RequestId,
-			Data:      res,
+			Data:      failure(fmt.Errorf("function %s isn't declared", request.Function)),
 		})
 		if err != nil {
-			return false
+			module.logger.Error("failed to send function response", "requestId", request.RequestId, "error", err)
 		}
-		_ = <-channel
-		return true
-	} else {
-		// Function wasn't found in the module.
 		return false
 	}
+
+	ctx := tracing.Extract(context.Background(), request.Meta)
+	if request.Deadline != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, time.Unix(0, request.Deadline*int64(time.Millisecond)))
+		defer cancel()
+	}
+	ctx, span := module.tracer.Start(ctx, request.Function, tracing.SpanKindServer)
+	defer span.End()
+
+	startedAt := time.Now()
+	res := module.handle(request, func(message models.BaseMessage) interface{} {
+		res := fn(contextWithMeta(ctx, message.GetMeta()), message.(models.FunctionCallRequest).Arguments)
+		if channel, ok := res.(chan interface{}); ok {
+			select {
+			case res = <-channel:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return res
+	})
+	module.metrics.observeHandler(request.Function, time.Since(startedAt))
+	if ctx.Err() != nil {
+		// The caller has given up on this call already.
+		span.RecordError(ctx.Err())
+		module.logger.Debug("discarded response past caller deadline", "function", request.Function, "requestId", request.RequestId)
+		return false
+	}
+	res = escapeResult(res)
+	if request.Function != chunkFunction {
+		res = module.chunkResponse(request.RequestId, res)
+	}
+	err := module.sendMessage(models.FunctionCallResponse{
+		RequestId: request.RequestId,
+		Data:      res,
+	})
+	if errors.Is(err, connection.ErrMessageTooLarge) {
+		// Fail the call rather than leaving the caller without an answer.
+		module.logger.Warn("function response over the outbound limit", "function", request.Function, "requestId", request.RequestId, "error", err)
+		err = module.sendMessage(models.FunctionCallResponse{
+			RequestId: request.RequestId,
+			Data:      failure(err),
+		})
+	}
+	if err != nil {
+		module.logger.Error("failed to send function response", "function", request.Function, "requestId", request.RequestId, "error", err)
+		return false
+	}
+	return true
 }
 
 func (module *JunoModule) executeHookTriggered(request models.TriggerHookResponse) bool {
-	if &request.Hook != nil {
-		module.hookListeners.RLock()
-		defer module.hookListeners.RUnlock()
+	if request.Hook != "" {
 		// Hook triggered by another module.
 		if request.Hook == `juno.activated` {
 			module.registered.Lock()
 			module.registered.value = true
-			if module.messageBuffer != nil {
-				_ = module.connection.Send(module.messageBuffer)
+			for _, buffered := range module.messageBuffer {
+				err := module.connection.Send(buffered)
+				if err != nil {
+					module.logger.Error("failed to flush buffered message", "error", err)
+				}
 			}
+			module.messageBuffer = [][]byte{}
 			module.registered.Unlock()
+			module.logger.Info("module activated")
+			return true
 		} else if request.Hook == `juno.deactivated` {
 			module.registered.Lock()
 			module.registered.value = false
 			module.registered.Unlock()
-		} else if module.hookListeners.m[request.Hook] != nil {
-			for _, listener := range module.hookListeners.m[request.Hook] {
-				listener(nil)
-			}
+			module.logger.Info("module deactivated")
+			return true
+		}
+
+		module.metrics.hookReceived(request.Hook)
+		listeners := module.listenersFor(request.Hook)
+		failures := []ListenerFailure{}
+		results := &queryResults{}
+		if len(listeners) > 0 {
+			ctx := tracing.Extract(context.Background(), request.Meta)
+			ctx, span := module.tracer.Start(ctx, request.Hook, tracing.SpanKindConsumer)
+			defer span.End()
+
+			module.handle(request, func(message models.BaseMessage) interface{} {
+				ctx := context.WithValue(contextWithMeta(ctx, message.GetMeta()), queryResultsKey{}, results)
+				event := HookEvent{
+					Hook: request.Hook,
+					Data: message.(models.TriggerHookResponse).Data,
+					Meta: message.GetMeta(),
+				}
+				for _, listener := range listeners {
+					err := listener.handler(ctx, event)
+					if err != nil {
+						span.RecordError(err)
+						module.logger.Error("hook listener failed", "hook", request.Hook, "pattern", listener.pattern, "listener", listener.id, "error", err)
+						failures = append(failures, ListenerFailure{Pattern: listener.pattern, Listener: listener.id, Error: err.Error()})
+					}
+				}
+				return nil
+			})
 		}
+		module.replyToHook(request.Meta, len(listeners), failures, results.values)
 		return true
 	} else {
 		// This module triggered the hook.
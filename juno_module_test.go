@@ -0,0 +1,155 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytesonus/juno-go/connection"
+	"github.com/bytesonus/juno-go/gateway"
+	"github.com/bytesonus/juno-go/models"
+)
+
+// startGateway runs an in-process gateway for the duration of the test.
+func startGateway(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := gateway.New()
+	go server.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String()
+}
+
+// startModule connects an initialized module to the gateway at address.
+// configure runs before Initialize.
+func startModule(t *testing.T, address, moduleId string, configure ...func(*JunoModule)) *JunoModule {
+	t.Helper()
+	module := Default(address)
+	for _, fn := range configure {
+		fn(&module)
+	}
+	channel, err := module.Initialize(moduleId, "1.0.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	await(t, channel)
+	t.Cleanup(func() { module.Close() })
+	return &module
+}
+
+// await returns the value delivered on channel, failing the test when none
+// arrives in time.
+func await(t *testing.T, channel chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case value := <-channel:
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a response")
+		return nil
+	}
+}
+
+func TestOversizedResponseFailsCall(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	await(t, mustSend(t)(server.DeclareFunction("big", func(map[string]interface{}) interface{} {
+		return strings.Repeat("x", 4096)
+	})))
+	client := startModule(t, address, "client", func(module *JunoModule) {
+		if err := module.SetMessageSizeLimits(1024, 1024); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	channel, err := client.CallFunctionContext(context.Background(), "server.big", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := await(t, channel)
+	if err, ok := result.(error); !ok || !errors.Is(err, connection.ErrMessageTooLarge) {
+		t.Fatalf("got %v, want ErrMessageTooLarge", result)
+	}
+}
+
+// mustSend fails the test when sending a request failed.
+func TestOversizedResultFailsCall(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server", func(module *JunoModule) {
+		if err := module.SetMessageSizeLimits(1024*1024, 1024); err != nil {
+			t.Fatal(err)
+		}
+	})
+	await(t, mustSend(t)(server.DeclareFunction("big", func(map[string]interface{}) interface{} {
+		return strings.Repeat("x", 4096)
+	})))
+	client := startModule(t, address, "client")
+
+	result := await(t, mustSend(t)(client.CallFunction("server.big", nil)))
+	var functionError *FunctionError
+	if err, ok := result.(error); !ok || !errors.As(err, &functionError) {
+		t.Fatalf("got %.60v, want a FunctionError", result)
+	}
+}
+
+func mustSend(t *testing.T) func(chan interface{}, error) chan interface{} {
+	return func(channel chan interface{}, err error) chan interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return channel
+	}
+}
+
+func TestCanceledCallResolves(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	await(t, mustSend(t)(server.DeclareFunction("hang", func(map[string]interface{}) interface{} {
+		return make(chan interface{})
+	})))
+	client := startModule(t, address, "client")
+
+	var requestId string
+	client.UseClientInterceptors(func(message models.BaseMessage, next Invoker) (chan interface{}, error) {
+		requestId = message.GetRequestId()
+		return next(message)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := mustSend(t)(client.CallFunctionContext(ctx, "server.hang", nil))
+	cancel()
+	if result := await(t, channel); result != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", result)
+	}
+	client.requests.Lock()
+	defer client.requests.Unlock()
+	if client.requests.m[requestId] != nil {
+		t.Error("the canceled request is still pending")
+	}
+}
+
+func TestDeadlineFollowsInterceptedRequest(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	await(t, mustSend(t)(server.DeclareFunction("hang", func(map[string]interface{}) interface{} {
+		return make(chan interface{})
+	})))
+	client := startModule(t, address, "client")
+	client.UseClientInterceptors(func(message models.BaseMessage, next Invoker) (chan interface{}, error) {
+		request := message.(models.FunctionCallRequest)
+		request.RequestId += "-replaced"
+		return next(request)
+	})
+
+	channel := mustSend(t)(client.CallFunction("server.hang", nil, WithTimeout(50*time.Millisecond)))
+	if result := await(t, channel); result != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", result)
+	}
+}
@@ -0,0 +1,40 @@
+package juno_go
+
+import (
+	"sync/atomic"
+
+	"github.com/bytesonus/juno-go/connection"
+	"github.com/bytesonus/juno-go/logging"
+	"github.com/bytesonus/juno-go/models"
+	"github.com/bytesonus/juno-go/utils/request_types"
+)
+
+// SetLogger routes the module's and its connection's diagnostics to logger.
+func (module *JunoModule) SetLogger(logger logging.Logger) {
+	module.logger = logger
+	if loggable, ok := module.connection.(connection.LoggableConnection); ok {
+		loggable.SetLogger(logger)
+	}
+}
+
+// SetDebug toggles logging every message sent or received at debug level.
+func (module *JunoModule) SetDebug(debug bool) {
+	var value int32
+	if debug {
+		value = 1
+	}
+	atomic.StoreInt32(&module.debug, value)
+}
+
+func (module *JunoModule) logMessage(direction string, message models.BaseMessage, size int) {
+	if atomic.LoadInt32(&module.debug) == 0 {
+		return
+	}
+	module.logger.Debug(
+		"message",
+		"direction", direction,
+		"type", request_types.Name(message.GetType()),
+		"requestId", message.GetRequestId(),
+		"bytes", size,
+	)
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger is a leveled key/value logger. Arguments alternate between keys and
+// values, so a *slog.Logger satisfies it as is.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func Nop() Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// TextLogger writes one logfmt-style line per entry.
+type TextLogger struct {
+	sync.Mutex
+	out io.Writer
+}
+
+func NewTextLogger(out io.Writer) *TextLogger {
+	return &TextLogger{out: out}
+}
+
+func (logger *TextLogger) Debug(msg string, args ...interface{}) {
+	logger.log("DEBUG", msg, args)
+}
+
+func (logger *TextLogger) Info(msg string, args ...interface{}) {
+	logger.log("INFO", msg, args)
+}
+
+func (logger *TextLogger) Warn(msg string, args ...interface{}) {
+	logger.log("WARN", msg, args)
+}
+
+func (logger *TextLogger) Error(msg string, args ...interface{}) {
+	logger.log("ERROR", msg, args)
+}
+
+func (logger *TextLogger) log(level, msg string, args []interface{}) {
+	var line strings.Builder
+	fmt.Fprintf(&line, "time=%s level=%s msg=%q", time.Now().Format(time.RFC3339Nano), level, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&line, " %v=%s", args[i], formatValue(args[i+1]))
+		} else {
+			fmt.Fprintf(&line, " !BADKEY=%s", formatValue(args[i]))
+		}
+	}
+	line.WriteByte('\n')
+
+	logger.Lock()
+	defer logger.Unlock()
+	_, _ = io.WriteString(logger.out, line.String())
+}
+
+func formatValue(value interface{}) string {
+	text := fmt.Sprint(value)
+	if err, ok := value.(error); ok {
+		text = err.Error()
+	}
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		return fmt.Sprintf("%q", text)
+	}
+	return text
+}
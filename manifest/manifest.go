@@ -0,0 +1,172 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	juno "github.com/bytesonus/juno-go"
+	"github.com/bytesonus/juno-go/connection"
+	"github.com/bytesonus/juno-go/protocol"
+	"github.com/bytesonus/juno-go/utils/semver"
+)
+
+const (
+	EnvModuleId     = "JUNO_MODULE_ID"
+	EnvVersion      = "JUNO_MODULE_VERSION"
+	EnvSocket       = "JUNO_SOCKET"
+	EnvUrl          = "JUNO_URL"
+	EnvDependencies = "JUNO_DEPENDENCIES"
+)
+
+// Manifest describes how a module registers with the gateway. It can be read
+// from a JSON or TOML file and overridden with environment variables.
+type Manifest struct {
+	ModuleId     string            `json:"moduleId"`
+	Version      string            `json:"version"`
+	Socket       string            `json:"socket,omitempty"`
+	Url          string            `json:"url,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// UnmarshalJSON accepts the module id as module_id too, the spelling TOML
+// manifests use.
+func (manifest *Manifest) UnmarshalJSON(data []byte) error {
+	type fields Manifest
+	var decoded struct {
+		fields
+		SnakeModuleId string `json:"module_id"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*manifest = Manifest(decoded.fields)
+	if manifest.ModuleId == "" {
+		manifest.ModuleId = decoded.SnakeModuleId
+	}
+	return nil
+}
+
+// Load reads the manifest at path, picking the format from its extension,
+// then applies environment overrides and validates the result.
+func Load(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, manifest)
+	case ".toml":
+		err = parseToml(string(data), manifest)
+	default:
+		return nil, fmt.Errorf("manifest: unsupported format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %s: %v", path, err)
+	}
+
+	manifest.ApplyEnv()
+	return manifest, manifest.Validate()
+}
+
+// FromEnv builds a manifest from environment variables alone.
+func FromEnv() (*Manifest, error) {
+	manifest := &Manifest{}
+	manifest.ApplyEnv()
+	return manifest, manifest.Validate()
+}
+
+// ApplyEnv overrides fields with the JUNO_* environment variables that are
+// set. JUNO_DEPENDENCIES holds comma separated name=range pairs.
+func (manifest *Manifest) ApplyEnv() {
+	if value, ok := os.LookupEnv(EnvModuleId); ok {
+		manifest.ModuleId = value
+	}
+	if value, ok := os.LookupEnv(EnvVersion); ok {
+		manifest.Version = value
+	}
+	if value, ok := os.LookupEnv(EnvSocket); ok {
+		manifest.Socket = value
+		manifest.Url = ""
+	}
+	if value, ok := os.LookupEnv(EnvUrl); ok {
+		manifest.Url = value
+	}
+	if value, ok := os.LookupEnv(EnvDependencies); ok {
+		manifest.Dependencies = map[string]string{}
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			parts := strings.SplitN(pair, "=", 2)
+			versionRange := "*"
+			if len(parts) == 2 {
+				versionRange = strings.TrimSpace(parts[1])
+			}
+			manifest.Dependencies[strings.TrimSpace(parts[0])] = versionRange
+		}
+	}
+}
+
+func (manifest *Manifest) Validate() error {
+	problems := []string{}
+	if manifest.ModuleId == "" {
+		problems = append(problems, "module id is missing")
+	} else if strings.ContainsAny(manifest.ModuleId, ". ") {
+		problems = append(problems, fmt.Sprintf("module id %q can't contain dots or spaces", manifest.ModuleId))
+	}
+	if _, err := semver.Parse(manifest.Version); err != nil {
+		problems = append(problems, fmt.Sprintf("version %q isn't a semantic version", manifest.Version))
+	}
+	if manifest.Socket == "" && manifest.Url == "" {
+		problems = append(problems, "either a socket or a url is required")
+	}
+
+	names := make([]string, 0, len(manifest.Dependencies))
+	for name := range manifest.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := semver.ParseRange(manifest.Dependencies[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("dependency %s has invalid version range %q", name, manifest.Dependencies[name]))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("manifest: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Connection creates the connection to the gateway described by the
+// manifest.
+func (manifest *Manifest) Connection() (connection.BaseConnection, error) {
+	if manifest.Url != "" {
+		return connection.FromUrl(manifest.Url)
+	}
+	return connection.NewUnixSocketConnection(manifest.Socket), nil
+}
+
+// Module creates a module connected as described by the manifest. Register
+// it with Initialize.
+func (manifest *Manifest) Module() (*juno.JunoModule, error) {
+	link, err := manifest.Connection()
+	if err != nil {
+		return nil, err
+	}
+	module := juno.NewJunoModule(protocol.NewJsonProtocol(), link)
+	return &module, nil
+}
+
+func (manifest *Manifest) Initialize(module *juno.JunoModule) (chan interface{}, error) {
+	return module.Initialize(manifest.ModuleId, manifest.Version, manifest.Dependencies)
+}
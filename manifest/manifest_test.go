@@ -0,0 +1,148 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setenv sets the JUNO_* variables for one test, and unsets the others.
+func setenv(t *testing.T, values map[string]string) {
+	for _, key := range []string{EnvModuleId, EnvVersion, EnvSocket, EnvUrl, EnvDependencies} {
+		previous, ok := os.LookupEnv(key)
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+		if value, set := values[key]; set {
+			os.Setenv(key, value)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+func write(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseToml(t *testing.T) {
+	manifest := &Manifest{}
+	err := parseToml(`
+# a full line comment
+module_id = "orders" # a trailing comment
+"version" = "1.2.0"
+socket = "/tmp/#not a comment"
+url = "tcp://\"quoted\"\\" # comment after an escaped backslash
+
+[dependencies]
+inventory = "^2.0.0"
+"billing" = "*"
+`, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{
+		ModuleId:     "orders",
+		Version:      "1.2.0",
+		Socket:       "/tmp/#not a comment",
+		Url:          `tcp://"quoted"\`,
+		Dependencies: map[string]string{"inventory": "^2.0.0", "billing": "*"},
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("got %+v, want %+v", manifest, want)
+	}
+}
+
+func TestParseTomlErrors(t *testing.T) {
+	for _, test := range []struct {
+		data, problem string
+	}{
+		{"[server]\nport = \"1\"", "line 1: unknown table [server]"},
+		{"module_id = \"orders\"\nname = \"x\"", "line 2: unknown key name"},
+		{"version = 1", "value of version must be a quoted string"},
+		{"module_id", "line 1: expected key = value"},
+		{"module_id = \"orders # unterminated", "value of module_id must be a quoted string"},
+	} {
+		err := parseToml(test.data, &Manifest{})
+		if err == nil || !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%q: got %v, want an error about %q", test.data, err, test.problem)
+		}
+	}
+}
+
+func TestLoadJsonAcceptsBothSpellings(t *testing.T) {
+	setenv(t, nil)
+	for _, key := range []string{"module_id", "moduleId"} {
+		path := write(t, "juno.json", `{"`+key+`": "orders", "version": "1.0.0", "socket": "/tmp/juno.sock"}`)
+		manifest, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+		if manifest.ModuleId != "orders" {
+			t.Errorf("%s: got module id %q", key, manifest.ModuleId)
+		}
+	}
+}
+
+func TestLoadAppliesEnv(t *testing.T) {
+	path := write(t, "juno.toml", `
+module_id = "orders"
+version = "1.0.0"
+url = "tcp://localhost:4000"
+
+[dependencies]
+inventory = "^2.0.0"
+`)
+	setenv(t, map[string]string{
+		EnvModuleId:     "billing",
+		EnvVersion:      "2.1.0",
+		EnvSocket:       "/tmp/juno.sock",
+		EnvDependencies: "orders=^1.0.0, inventory ,",
+	})
+	manifest, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{
+		ModuleId:     "billing",
+		Version:      "2.1.0",
+		Socket:       "/tmp/juno.sock",
+		Dependencies: map[string]string{"orders": "^1.0.0", "inventory": "*"},
+	}
+	if !reflect.DeepEqual(manifest, want) {
+		t.Errorf("got %+v, want %+v", manifest, want)
+	}
+}
+
+func TestFromEnvValidates(t *testing.T) {
+	setenv(t, map[string]string{
+		EnvModuleId:     "or.ders",
+		EnvVersion:      "latest",
+		EnvDependencies: "inventory=two",
+	})
+	_, err := FromEnv()
+	if err == nil {
+		t.Fatal("invalid manifest was accepted")
+	}
+	for _, problem := range []string{
+		`module id "or.ders" can't contain dots or spaces`,
+		`version "latest" isn't a semantic version`,
+		"either a socket or a url is required",
+		`dependency inventory has invalid version range "two"`,
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("%v doesn't mention %s", err, problem)
+		}
+	}
+}
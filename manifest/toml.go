@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseToml understands the subset of TOML a manifest needs: top-level
+// string keys and a [dependencies] table of string values.
+//
+//	module_id = "orders"
+//	version = "1.2.0"
+//	socket = "/var/run/juno.sock"
+//
+//	[dependencies]
+//	inventory = "^2.0.0"
+func parseToml(data string, manifest *Manifest) error {
+	table := ""
+	for number, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table != "dependencies" {
+				return fmt.Errorf("line %d: unknown table [%s]", number+1, table)
+			}
+			if manifest.Dependencies == nil {
+				manifest.Dependencies = map[string]string{}
+			}
+			continue
+		}
+
+		separator := strings.Index(line, "=")
+		if separator < 0 {
+			return fmt.Errorf("line %d: expected key = value", number+1)
+		}
+		key := unquote(strings.TrimSpace(line[:separator]))
+		value, err := strconv.Unquote(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			return fmt.Errorf("line %d: value of %s must be a quoted string", number+1, key)
+		}
+
+		if table == "dependencies" {
+			manifest.Dependencies[key] = value
+			continue
+		}
+		switch key {
+		case "module_id", "moduleId":
+			manifest.ModuleId = value
+		case "version":
+			manifest.Version = value
+		case "socket":
+			manifest.Socket = value
+		case "url":
+			manifest.Url = value
+		default:
+			return fmt.Errorf("line %d: unknown key %s", number+1, key)
+		}
+	}
+	return nil
+}
+
+func stripComment(line string) string {
+	quoted, escaped := false, false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == '#' && !quoted:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquote(key string) string {
+	if unquoted, err := strconv.Unquote(key); err == nil {
+		return unquoted
+	}
+	return key
+}
@@ -0,0 +1,70 @@
+package juno_go
+
+import (
+	"context"
+	"time"
+)
+
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	meta     map[string]string
+	deadline time.Time
+	// receipts is how many receipts TriggerHookAcknowledged waits for.
+	receipts int
+}
+
+// WithMeta attaches a single metadata entry to an outgoing request.
+func WithMeta(key, value string) CallOption {
+	return func(options *callOptions) {
+		options.meta[key] = value
+	}
+}
+
+// WithMetadata attaches every entry of meta to an outgoing request.
+func WithMetadata(meta map[string]string) CallOption {
+	return func(options *callOptions) {
+		for key, value := range meta {
+			options.meta[key] = value
+		}
+	}
+}
+
+// WithDeadline abandons a function call that hasn't been answered by
+// deadline. The deadline is also sent to the function's handler.
+func WithDeadline(deadline time.Time) CallOption {
+	return func(options *callOptions) {
+		if options.deadline.IsZero() || deadline.Before(options.deadline) {
+			options.deadline = deadline
+		}
+	}
+}
+
+// WithTimeout is shorthand for WithDeadline(time.Now().Add(timeout)).
+func WithTimeout(timeout time.Duration) CallOption {
+	return WithDeadline(time.Now().Add(timeout))
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	options := &callOptions{meta: map[string]string{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+type metaKey struct{}
+
+func contextWithMeta(ctx context.Context, meta map[string]string) context.Context {
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFromContext returns the metadata of the request being handled, or nil
+// when ctx doesn't belong to a function or hook handler.
+func MetaFromContext(ctx context.Context) map[string]string {
+	meta, _ := ctx.Value(metaKey{}).(map[string]string)
+	return meta
+}
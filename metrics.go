@@ -0,0 +1,249 @@
+package juno_go
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytesonus/juno-go/utils/request_types"
+)
+
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type HistogramSnapshot struct {
+	// Buckets holds the upper bounds, in seconds, and Counts the cumulative
+	// number of observations less than or equal to each of them.
+	Buckets []float64
+	Counts  []uint64
+	Count   uint64
+	Sum     float64
+}
+
+type MetricsSnapshot struct {
+	MessagesSent     map[string]uint64
+	MessagesReceived map[string]uint64
+	FunctionCalls    map[string]HistogramSnapshot
+	FunctionHandlers map[string]HistogramSnapshot
+	HooksTriggered   map[string]uint64
+	HooksReceived    map[string]uint64
+	PendingRequests  int
+	EncodeErrors     uint64
+	DecodeErrors     uint64
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type MetricsType struct {
+	sync.Mutex
+	sent          map[uint64]uint64
+	received      map[uint64]uint64
+	calls         map[string]*histogram
+	handlers      map[string]*histogram
+	hooksSent     map[string]uint64
+	hooksReceived map[string]uint64
+	encodeErrors  uint64
+	decodeErrors  uint64
+}
+
+func newMetrics() MetricsType {
+	return MetricsType{
+		sent:          make(map[uint64]uint64),
+		received:      make(map[uint64]uint64),
+		calls:         make(map[string]*histogram),
+		handlers:      make(map[string]*histogram),
+		hooksSent:     make(map[string]uint64),
+		hooksReceived: make(map[string]uint64),
+	}
+}
+
+func (metrics *MetricsType) messageSent(requestType uint64) {
+	metrics.Lock()
+	metrics.sent[requestType]++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) messageReceived(requestType uint64) {
+	metrics.Lock()
+	metrics.received[requestType]++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) hookTriggered(hook string) {
+	metrics.Lock()
+	metrics.hooksSent[hook]++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) hookReceived(hook string) {
+	metrics.Lock()
+	metrics.hooksReceived[hook]++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) encodeError() {
+	metrics.Lock()
+	metrics.encodeErrors++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) decodeError() {
+	metrics.Lock()
+	metrics.decodeErrors++
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) observeCall(function string, duration time.Duration) {
+	metrics.Lock()
+	observe(metrics.calls, function, duration)
+	metrics.Unlock()
+}
+
+func (metrics *MetricsType) observeHandler(function string, duration time.Duration) {
+	metrics.Lock()
+	observe(metrics.handlers, function, duration)
+	metrics.Unlock()
+}
+
+func observe(histograms map[string]*histogram, name string, duration time.Duration) {
+	h := histograms[name]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(DefaultLatencyBuckets))}
+		histograms[name] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range DefaultLatencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Metrics returns a point-in-time copy of the module's counters. There is no
+// reconnect count, since a module never reconnects: once its connection is
+// lost, a new module has to be initialized.
+func (module *JunoModule) Metrics() MetricsSnapshot {
+	module.requests.RLock()
+	pending := len(module.requests.m)
+	module.requests.RUnlock()
+
+	metrics := &module.metrics
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	snapshot := MetricsSnapshot{
+		MessagesSent:     make(map[string]uint64),
+		MessagesReceived: make(map[string]uint64),
+		FunctionCalls:    make(map[string]HistogramSnapshot),
+		FunctionHandlers: make(map[string]HistogramSnapshot),
+		HooksTriggered:   make(map[string]uint64),
+		HooksReceived:    make(map[string]uint64),
+		PendingRequests:  pending,
+		EncodeErrors:     metrics.encodeErrors,
+		DecodeErrors:     metrics.decodeErrors,
+	}
+	for requestType, count := range metrics.sent {
+		snapshot.MessagesSent[request_types.Name(requestType)] += count
+	}
+	for requestType, count := range metrics.received {
+		snapshot.MessagesReceived[request_types.Name(requestType)] += count
+	}
+	for function, h := range metrics.calls {
+		snapshot.FunctionCalls[function] = h.snapshot()
+	}
+	for function, h := range metrics.handlers {
+		snapshot.FunctionHandlers[function] = h.snapshot()
+	}
+	for hook, count := range metrics.hooksSent {
+		snapshot.HooksTriggered[hook] = count
+	}
+	for hook, count := range metrics.hooksReceived {
+		snapshot.HooksReceived[hook] = count
+	}
+	return snapshot
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	return HistogramSnapshot{
+		Buckets: append([]float64{}, DefaultLatencyBuckets...),
+		Counts:  append([]uint64{}, h.counts...),
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+}
+
+// MetricsHandler serves the module's metrics in the Prometheus text
+// exposition format. Like Metrics, it has no reconnect count.
+func (module *JunoModule) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		module.Metrics().WritePrometheus(w)
+	})
+}
+
+func (snapshot MetricsSnapshot) WritePrometheus(w io.Writer) {
+	writeCounters(w, "juno_messages_sent_total", "Messages sent by type.", "type", snapshot.MessagesSent)
+	writeCounters(w, "juno_messages_received_total", "Messages received by type.", "type", snapshot.MessagesReceived)
+	writeHistograms(w, "juno_function_call_duration_seconds", "Latency of outgoing function calls.", snapshot.FunctionCalls)
+	writeHistograms(w, "juno_function_handler_duration_seconds", "Execution time of declared functions.", snapshot.FunctionHandlers)
+	writeCounters(w, "juno_hooks_triggered_total", "Hooks triggered by this module.", "hook", snapshot.HooksTriggered)
+	writeCounters(w, "juno_hooks_received_total", "Hooks delivered to this module.", "hook", snapshot.HooksReceived)
+
+	fmt.Fprintf(w, "# HELP juno_pending_requests Requests awaiting a response.\n# TYPE juno_pending_requests gauge\n")
+	fmt.Fprintf(w, "juno_pending_requests %d\n", snapshot.PendingRequests)
+	fmt.Fprintf(w, "# HELP juno_encode_errors_total Messages that failed to encode.\n# TYPE juno_encode_errors_total counter\n")
+	fmt.Fprintf(w, "juno_encode_errors_total %d\n", snapshot.EncodeErrors)
+	fmt.Fprintf(w, "# HELP juno_decode_errors_total Frames that failed to decode.\n# TYPE juno_decode_errors_total counter\n")
+	fmt.Fprintf(w, "juno_decode_errors_total %d\n", snapshot.DecodeErrors)
+}
+
+func writeCounters(w io.Writer, name, help, label string, values map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", name, label, escapeLabel(key), values[key])
+	}
+}
+
+func writeHistograms(w io.Writer, name, help string, values map[string]HistogramSnapshot) {
+	functions := make([]string, 0, len(values))
+	for function := range values {
+		functions = append(functions, function)
+	}
+	sort.Strings(functions)
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, function := range functions {
+		h := values[function]
+		label := escapeLabel(function)
+		for i, bound := range h.Buckets {
+			fmt.Fprintf(w, "%s_bucket{function=\"%s\",le=\"%g\"} %d\n", name, label, bound, h.Counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{function=\"%s\",le=\"+Inf\"} %d\n", name, label, h.Count)
+		fmt.Fprintf(w, "%s_sum{function=\"%s\"} %g\n", name, label, h.Sum)
+		fmt.Fprintf(w, "%s_count{function=\"%s\"} %d\n", name, label, h.Count)
+	}
+}
+
+func sortedKeys(values map[string]uint64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,38 @@
+package juno_go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	module := &JunoModule{metrics: newMetrics(), requests: RequestListType{m: map[string]*pendingRequest{}}}
+	metrics := &module.metrics
+	metrics.messageSent(3)
+	metrics.messageSent(3)
+	metrics.hookTriggered("orders.\"created\"\n\\")
+	metrics.observeCall("orders.place", 7*time.Millisecond)
+	metrics.observeCall("orders.place", 30*time.Second)
+
+	var output bytes.Buffer
+	module.Metrics().WritePrometheus(&output)
+	for _, want := range []string{
+		`juno_messages_sent_total{type="FunctionCallRequest"} 2`,
+		`juno_hooks_triggered_total{hook="orders.\"created\"\n\\"} 1`,
+		`juno_function_call_duration_seconds_bucket{function="orders.place",le="0.005"} 0`,
+		`juno_function_call_duration_seconds_bucket{function="orders.place",le="0.01"} 1`,
+		`juno_function_call_duration_seconds_bucket{function="orders.place",le="10"} 1`,
+		`juno_function_call_duration_seconds_bucket{function="orders.place",le="+Inf"} 2`,
+		`juno_function_call_duration_seconds_count{function="orders.place"} 2`,
+		`juno_pending_requests 0`,
+	} {
+		if !strings.Contains(output.String(), want+"\n") {
+			t.Errorf("output is missing %s", want)
+		}
+	}
+	if strings.Contains(output.String(), "reconnect") {
+		t.Error("output has a reconnect count")
+	}
+}
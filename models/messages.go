@@ -7,6 +7,7 @@ import (
 type BaseMessage interface {
 	GetType() uint64
 	GetRequestId() string
+	GetMeta() map[string]string
 }
 
 type RegisterModuleRequest struct {
@@ -14,6 +15,7 @@ type RegisterModuleRequest struct {
 	ModuleId     string            `json:"moduleId"`
 	Version      string            `json:"version"`
 	Dependencies map[string]string `json:"dependencies"`
+	Meta         map[string]string `json:"meta,omitempty"`
 }
 
 func (message RegisterModuleRequest) GetType() uint64 {
@@ -24,8 +26,13 @@ func (message RegisterModuleRequest) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message RegisterModuleRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type RegisterModuleResponse struct {
-	RequestId string `json:"requestId"`
+	RequestId string            `json:"requestId"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message RegisterModuleResponse) GetType() uint64 {
@@ -36,10 +43,18 @@ func (message RegisterModuleResponse) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message RegisterModuleResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type FunctionCallRequest struct {
 	RequestId string                 `json:"requestId"`
 	Function  string                 `json:"function"`
 	Arguments map[string]interface{} `json:"arguments"`
+	Meta      map[string]string      `json:"meta,omitempty"`
+	// Deadline is the caller's deadline in milliseconds since the Unix
+	// epoch, or 0 when the caller doesn't have one.
+	Deadline int64 `json:"deadline,omitempty"`
 }
 
 func (message FunctionCallRequest) GetType() uint64 {
@@ -50,9 +65,14 @@ func (message FunctionCallRequest) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message FunctionCallRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type FunctionCallResponse struct {
-	RequestId string      `json:"requestId"`
-	Data      interface{} `json:"data"`
+	RequestId string            `json:"requestId"`
+	Data      interface{}       `json:"data"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message FunctionCallResponse) GetType() uint64 {
@@ -63,9 +83,14 @@ func (message FunctionCallResponse) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message FunctionCallResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type RegisterHookRequest struct {
-	RequestId string `json:"requestId"`
-	Hook      string `json:"hook"`
+	RequestId string            `json:"requestId"`
+	Hook      string            `json:"hook"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message RegisterHookRequest) GetType() uint64 {
@@ -76,8 +101,13 @@ func (message RegisterHookRequest) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message RegisterHookRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type RegisterHookResponse struct {
-	RequestId string `json:"requestId"`
+	RequestId string            `json:"requestId"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message RegisterHookResponse) GetType() uint64 {
@@ -88,10 +118,15 @@ func (message RegisterHookResponse) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message RegisterHookResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type TriggerHookRequest struct {
-	RequestId string      `json:"requestId"`
-	Hook      string      `json:"hook"`
-	Data      interface{} `json:"data"`
+	RequestId string            `json:"requestId"`
+	Hook      string            `json:"hook"`
+	Data      interface{}       `json:"data"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message TriggerHookRequest) GetType() uint64 {
@@ -102,9 +137,15 @@ func (message TriggerHookRequest) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message TriggerHookRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type TriggerHookResponse struct {
-	RequestId string `json:"requestId"`
-	Hook      string `json:"hook"`
+	RequestId string            `json:"requestId"`
+	Hook      string            `json:"hook"`
+	Data      interface{}       `json:"data"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message TriggerHookResponse) GetType() uint64 {
@@ -115,9 +156,14 @@ func (message TriggerHookResponse) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message TriggerHookResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type DeclareFunctionRequest struct {
-	RequestId string `json:"requestId"`
-	Function  string `json:"function"`
+	RequestId string            `json:"requestId"`
+	Function  string            `json:"function"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message DeclareFunctionRequest) GetType() uint64 {
@@ -128,9 +174,14 @@ func (message DeclareFunctionRequest) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message DeclareFunctionRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type DeclareFunctionResponse struct {
-	RequestId string `json:"requestId"`
-	Function  string `json:"function"`
+	RequestId string            `json:"requestId"`
+	Function  string            `json:"function"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message DeclareFunctionResponse) GetType() uint64 {
@@ -141,9 +192,85 @@ func (message DeclareFunctionResponse) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message DeclareFunctionResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
+type UnregisterHookRequest struct {
+	RequestId string            `json:"requestId"`
+	Hook      string            `json:"hook"`
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+func (message UnregisterHookRequest) GetType() uint64 {
+	return request_types.UnregisterHookRequest
+}
+
+func (message UnregisterHookRequest) GetRequestId() string {
+	return message.RequestId
+}
+
+func (message UnregisterHookRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
+type UnregisterHookResponse struct {
+	RequestId string            `json:"requestId"`
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+func (message UnregisterHookResponse) GetType() uint64 {
+	return request_types.UnregisterHookResponse
+}
+
+func (message UnregisterHookResponse) GetRequestId() string {
+	return message.RequestId
+}
+
+func (message UnregisterHookResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
+type UndeclareFunctionRequest struct {
+	RequestId string            `json:"requestId"`
+	Function  string            `json:"function"`
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+func (message UndeclareFunctionRequest) GetType() uint64 {
+	return request_types.UndeclareFunctionRequest
+}
+
+func (message UndeclareFunctionRequest) GetRequestId() string {
+	return message.RequestId
+}
+
+func (message UndeclareFunctionRequest) GetMeta() map[string]string {
+	return message.Meta
+}
+
+type UndeclareFunctionResponse struct {
+	RequestId string            `json:"requestId"`
+	Function  string            `json:"function"`
+	Meta      map[string]string `json:"meta,omitempty"`
+}
+
+func (message UndeclareFunctionResponse) GetType() uint64 {
+	return request_types.UndeclareFunctionResponse
+}
+
+func (message UndeclareFunctionResponse) GetRequestId() string {
+	return message.RequestId
+}
+
+func (message UndeclareFunctionResponse) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type ErrorMessage struct {
-	RequestId string `json:"requestId"`
-	Error     uint32 `json:"error"`
+	RequestId string            `json:"requestId"`
+	Error     uint32            `json:"error"`
+	Meta      map[string]string `json:"meta,omitempty"`
 }
 
 func (message ErrorMessage) GetType() uint64 {
@@ -154,6 +281,10 @@ func (message ErrorMessage) GetRequestId() string {
 	return message.RequestId
 }
 
+func (message ErrorMessage) GetMeta() map[string]string {
+	return message.Meta
+}
+
 type UnknownMessage struct {
 	RequestId string `json:"requestId"`
 }
@@ -165,3 +296,7 @@ func (message UnknownMessage) GetType() uint64 {
 func (message UnknownMessage) GetRequestId() string {
 	return message.RequestId
 }
+
+func (message UnknownMessage) GetMeta() map[string]string {
+	return nil
+}
@@ -2,10 +2,15 @@ package protocol
 
 import (
 	"fmt"
-	"github.com/bytesonus/juno-go/models"
+	"sync/atomic"
 	"time"
+
+	"github.com/bytesonus/juno-go/models"
 )
 
+// requestCounter keeps ids generated within the same clock tick apart.
+var requestCounter uint64
+
 type BaseProtocol interface {
 	Encode(models.BaseMessage) ([]byte, error)
 	Decode([]byte) models.BaseMessage
@@ -14,7 +19,7 @@ type BaseProtocol interface {
 }
 
 func GenerateRequestId(moduleId string) string {
-	return fmt.Sprintf("%s-%d", moduleId, time.Now().UnixNano())
+	return fmt.Sprintf("%s-%d-%d", moduleId, time.Now().UnixNano(), atomic.AddUint64(&requestCounter, 1))
 }
 
 func Initialize(protocol BaseProtocol, moduleId, version string, dependencies map[string]string) models.BaseMessage {
@@ -34,6 +39,13 @@ func RegisterHook(protocol BaseProtocol, hook string) models.BaseMessage {
 	}
 }
 
+func UnregisterHook(protocol BaseProtocol, hook string) models.BaseMessage {
+	return models.UnregisterHookRequest{
+		RequestId: GenerateRequestId(protocol.GetModuleId()),
+		Hook:      hook,
+	}
+}
+
 func TriggerHook(protocol BaseProtocol, hook string, data interface{}) models.BaseMessage {
 	return models.TriggerHookRequest{
 		RequestId: GenerateRequestId(protocol.GetModuleId()),
@@ -49,6 +61,13 @@ func DeclareFunction(protocol BaseProtocol, function string) models.BaseMessage
 	}
 }
 
+func UndeclareFunction(protocol BaseProtocol, function string) models.BaseMessage {
+	return models.UndeclareFunctionRequest{
+		RequestId: GenerateRequestId(protocol.GetModuleId()),
+		Function:  function,
+	}
+}
+
 func CallFunction(protocol BaseProtocol, function string, arguments map[string]interface{}) models.BaseMessage {
 	return models.FunctionCallRequest{
 		RequestId: GenerateRequestId(protocol.GetModuleId()),
@@ -27,6 +27,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Version:      request.Version,
 				request_keys.Dependencies: request.Dependencies,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.RegisterModuleResponse:
@@ -35,6 +36,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.RequestId: request.RequestId,
 				request_keys.Type:      request_types.RegisterModuleResponse,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.FunctionCallRequest:
@@ -45,6 +47,10 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Function:  request.Function,
 				request_keys.Arguments: request.Arguments,
 			}
+			withMeta(genericMap, request.Meta)
+			if request.Deadline != 0 {
+				genericMap[request_keys.Deadline] = request.Deadline
+			}
 			break
 		}
 	case models.FunctionCallResponse:
@@ -54,6 +60,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Type:      request_types.FunctionCallResponse,
 				request_keys.Data:      request.Data,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.RegisterHookRequest:
@@ -63,6 +70,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Type:      request_types.RegisterHookRequest,
 				request_keys.Hook:      request.Hook,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.RegisterHookResponse:
@@ -71,6 +79,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.RequestId: request.RequestId,
 				request_keys.Type:      request_types.RegisterHookResponse,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.TriggerHookRequest:
@@ -79,7 +88,9 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.RequestId: request.RequestId,
 				request_keys.Type:      request_types.TriggerHookRequest,
 				request_keys.Hook:      request.Hook,
+				request_keys.Data:      request.Data,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.TriggerHookResponse:
@@ -87,7 +98,10 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 			genericMap = map[string]interface{}{
 				request_keys.RequestId: request.RequestId,
 				request_keys.Type:      request_types.TriggerHookResponse,
+				request_keys.Hook:      request.Hook,
+				request_keys.Data:      request.Data,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.DeclareFunctionRequest:
@@ -97,6 +111,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Type:      request_types.DeclareFunctionRequest,
 				request_keys.Function:  request.Function,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.DeclareFunctionResponse:
@@ -106,6 +121,46 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Type:      request_types.DeclareFunctionResponse,
 				request_keys.Function:  request.Function,
 			}
+			withMeta(genericMap, request.Meta)
+			break
+		}
+	case models.UnregisterHookRequest:
+		{
+			genericMap = map[string]interface{}{
+				request_keys.RequestId: request.RequestId,
+				request_keys.Type:      request_types.UnregisterHookRequest,
+				request_keys.Hook:      request.Hook,
+			}
+			withMeta(genericMap, request.Meta)
+			break
+		}
+	case models.UnregisterHookResponse:
+		{
+			genericMap = map[string]interface{}{
+				request_keys.RequestId: request.RequestId,
+				request_keys.Type:      request_types.UnregisterHookResponse,
+			}
+			withMeta(genericMap, request.Meta)
+			break
+		}
+	case models.UndeclareFunctionRequest:
+		{
+			genericMap = map[string]interface{}{
+				request_keys.RequestId: request.RequestId,
+				request_keys.Type:      request_types.UndeclareFunctionRequest,
+				request_keys.Function:  request.Function,
+			}
+			withMeta(genericMap, request.Meta)
+			break
+		}
+	case models.UndeclareFunctionResponse:
+		{
+			genericMap = map[string]interface{}{
+				request_keys.RequestId: request.RequestId,
+				request_keys.Type:      request_types.UndeclareFunctionResponse,
+				request_keys.Function:  request.Function,
+			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.ErrorMessage:
@@ -115,6 +170,7 @@ func (protocol *JsonProtocol) Encode(message models.BaseMessage) ([]byte, error)
 				request_keys.Type:      request_types.Error,
 				request_keys.Error:     request.Error,
 			}
+			withMeta(genericMap, request.Meta)
 			break
 		}
 	case models.UnknownMessage:
@@ -142,7 +198,12 @@ func (protocol *JsonProtocol) Decode(data []byte) models.BaseMessage {
 		return models.UnknownMessage{RequestId: "undefined"}
 	}
 
-	switch message["type"].(float64) {
+	messageType, ok := message[request_keys.Type].(float64)
+	if !ok {
+		return models.UnknownMessage{RequestId: "undefined"}
+	}
+
+	switch messageType {
 	case request_types.RegisterModuleRequest:
 		{
 			var request models.RegisterModuleRequest
@@ -233,6 +294,42 @@ func (protocol *JsonProtocol) Decode(data []byte) models.BaseMessage {
 			}
 			return request
 		}
+	case request_types.UnregisterHookRequest:
+		{
+			var request models.UnregisterHookRequest
+			err := json.Unmarshal(data, &request)
+			if err != nil {
+				return models.UnknownMessage{RequestId: "undefined"}
+			}
+			return request
+		}
+	case request_types.UnregisterHookResponse:
+		{
+			var request models.UnregisterHookResponse
+			err := json.Unmarshal(data, &request)
+			if err != nil {
+				return models.UnknownMessage{RequestId: "undefined"}
+			}
+			return request
+		}
+	case request_types.UndeclareFunctionRequest:
+		{
+			var request models.UndeclareFunctionRequest
+			err := json.Unmarshal(data, &request)
+			if err != nil {
+				return models.UnknownMessage{RequestId: "undefined"}
+			}
+			return request
+		}
+	case request_types.UndeclareFunctionResponse:
+		{
+			var request models.UndeclareFunctionResponse
+			err := json.Unmarshal(data, &request)
+			if err != nil {
+				return models.UnknownMessage{RequestId: "undefined"}
+			}
+			return request
+		}
 	case request_types.Error:
 		{
 			var request models.ErrorMessage
@@ -254,6 +351,12 @@ func (protocol *JsonProtocol) Decode(data []byte) models.BaseMessage {
 	}
 }
 
+func withMeta(genericMap map[string]interface{}, meta map[string]string) {
+	if len(meta) > 0 {
+		genericMap[request_keys.Meta] = meta
+	}
+}
+
 func (protocol *JsonProtocol) SetModuleId(moduleId string) {
 	protocol.moduleId = moduleId
 }
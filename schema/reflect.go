@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// TypeOf returns the type expression describing values of the Go type t as
+// they travel as JSON. Named struct types are added to types under their Go
+// name. Types that marshal themselves to text, such as time.Time, are strings,
+// and other json.Marshalers are any, since their shape isn't known.
+func TypeOf(t reflect.Type, types map[string]Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case implements(t, textMarshaler):
+		return "string"
+	case implements(t, jsonMarshaler):
+		return "any"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// encoding/json sends []byte as a base64 string.
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Slice, reflect.Array:
+		return "[]" + TypeOf(t.Elem(), types)
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			if !implements(t.Key(), textMarshaler) {
+				return "object"
+			}
+		}
+		return "map[string]" + TypeOf(t.Elem(), types)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "object"
+		}
+		if _, ok := types[t.Name()]; !ok {
+			// Register the name first so that recursive types terminate.
+			types[t.Name()] = Type{}
+			types[t.Name()] = Type{Fields: FieldsOf(t, types)}
+		}
+		return t.Name()
+	default:
+		return "any"
+	}
+}
+
+func implements(t reflect.Type, marshaler reflect.Type) bool {
+	return t.Implements(marshaler) || reflect.PtrTo(t).Implements(marshaler)
+}
+
+// FieldsOf describes the exported fields of the struct type t by their JSON
+// names.
+func FieldsOf(t reflect.Type, types map[string]Type) map[string]string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := map[string]string{}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields[name] = TypeOf(field.Type, types)
+	}
+	return fields
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type rawPayload struct{}
+
+func (rawPayload) MarshalJSON() ([]byte, error) {
+	return []byte(`[1, 2]`), nil
+}
+
+type event struct {
+	Id      string            `json:"id"`
+	At      time.Time         `json:"at"`
+	Body    []byte            `json:"body"`
+	Digest  [4]byte           `json:"digest"`
+	Counts  map[int]int       `json:"counts"`
+	Raw     rawPayload        `json:"raw"`
+	Parent  *event            `json:"parent"`
+	Ignored string            `json:"-"`
+	Labels  map[string][]byte `json:"labels"`
+}
+
+func TestTypeOf(t *testing.T) {
+	types := map[string]Type{}
+	if got := TypeOf(reflect.TypeOf(event{}), types); got != "event" {
+		t.Fatalf("got %q, want event", got)
+	}
+	want := map[string]string{
+		"id":     "string",
+		"at":     "string",
+		"body":   "string",
+		"digest": "[]int",
+		"counts": "map[string]int",
+		"raw":    "any",
+		"parent": "event",
+		"labels": "map[string]string",
+	}
+	if !reflect.DeepEqual(types["event"].Fields, want) {
+		t.Errorf("got fields %v, want %v", types["event"].Fields, want)
+	}
+
+	// Check the expressions against what encoding/json actually sends.
+	data, err := json.Marshal(event{Body: []byte("hi"), Counts: map[int]int{1: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := map[string]interface{}{}
+	if err := json.Unmarshal(data, &sent); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"at", "body"} {
+		if _, ok := sent[field].(string); !ok {
+			t.Errorf("%s was sent as %T, not a string", field, sent[field])
+		}
+	}
+	if _, ok := sent["counts"].(map[string]interface{}); !ok {
+		t.Errorf("counts was sent as %T, not an object", sent["counts"])
+	}
+}
@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema describes the functions and hooks a module offers. Argument, result,
+// field and hook data types are type expressions:
+//
+//	string, int, float, bool, any, object
+//	[]T
+//	map[string]T
+//	the name of an entry in Types
+type Schema struct {
+	Module    string              `json:"module"`
+	Types     map[string]Type     `json:"types,omitempty"`
+	Functions map[string]Function `json:"functions,omitempty"`
+	Hooks     map[string]Hook     `json:"hooks,omitempty"`
+}
+
+type Type struct {
+	Description string            `json:"description,omitempty"`
+	Fields      map[string]string `json:"fields"`
+}
+
+type Function struct {
+	Description string            `json:"description,omitempty"`
+	Args        map[string]string `json:"args,omitempty"`
+	// Result is empty for functions that return nothing.
+	Result string `json:"result,omitempty"`
+}
+
+type Hook struct {
+	Description string `json:"description,omitempty"`
+	Data        string `json:"data,omitempty"`
+}
+
+var primitives = map[string]bool{
+	"string": true,
+	"int":    true,
+	"float":  true,
+	"bool":   true,
+	"any":    true,
+	"object": true,
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+func Load(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return schema, nil
+}
+
+func Parse(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, schema.Validate()
+}
+
+func (schema *Schema) Validate() error {
+	problems := []string{}
+	if schema.Module == "" || strings.ContainsAny(schema.Module, ". ") {
+		problems = append(problems, fmt.Sprintf("invalid module id %q", schema.Module))
+	}
+	check := func(where, expression string) {
+		if err := schema.checkType(expression); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", where, err))
+		}
+	}
+
+	for _, name := range sortedKeys(schema.Types) {
+		if !namePattern.MatchString(name) || primitives[name] {
+			problems = append(problems, fmt.Sprintf("invalid type name %q", name))
+		}
+		for _, field := range sortedKeys(schema.Types[name].Fields) {
+			check("type "+name+" field "+field, schema.Types[name].Fields[field])
+		}
+	}
+	for _, name := range sortedKeys(schema.Functions) {
+		if !namePattern.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("invalid function name %q", name))
+		}
+		function := schema.Functions[name]
+		for _, arg := range sortedKeys(function.Args) {
+			check("function "+name+" argument "+arg, function.Args[arg])
+		}
+		if function.Result != "" {
+			check("function "+name+" result", function.Result)
+		}
+	}
+	for _, name := range sortedKeys(schema.Hooks) {
+		if !namePattern.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("invalid hook name %q", name))
+		}
+		if schema.Hooks[name].Data != "" {
+			check("hook "+name+" data", schema.Hooks[name].Data)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("schema: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (schema *Schema) checkType(expression string) error {
+	switch {
+	case primitives[expression]:
+		return nil
+	case strings.HasPrefix(expression, "[]"):
+		return schema.checkType(expression[2:])
+	case strings.HasPrefix(expression, "map[string]"):
+		return schema.checkType(expression[len("map[string]"):])
+	}
+	if _, ok := schema.Types[expression]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown type %q", expression)
+}
+
+// TypeNames, FunctionNames, HookNames and FieldNames return sorted names, so
+// that generated code lists them in a stable order.
+func (schema *Schema) TypeNames() []string {
+	return sortedKeys(schema.Types)
+}
+
+func (schema *Schema) FunctionNames() []string {
+	return sortedKeys(schema.Functions)
+}
+
+func (schema *Schema) HookNames() []string {
+	return sortedKeys(schema.Hooks)
+}
+
+func FieldNames(fields map[string]string) []string {
+	return sortedKeys(fields)
+}
+
+func sortedKeys(values interface{}) []string {
+	keys := []string{}
+	switch typed := values.(type) {
+	case map[string]string:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	case map[string]Type:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	case map[string]Function:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	case map[string]Hook:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,384 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	streamNextFunction   = "__stream_next"
+	streamCancelFunction = "__stream_cancel"
+	streamEnvelope       = "__stream"
+	// streamPollTimeout bounds how long a pull waits for the handler, and
+	// streamIdleTimeout how long a stream lives without being pulled.
+	streamPollTimeout = 5 * time.Second
+	streamIdleTimeout = time.Minute
+)
+
+// DefaultStreamWindow is how many items a stream handler can send ahead of
+// the caller before Send blocks.
+var DefaultStreamWindow = 16
+
+// ErrStreamCanceled is returned by StreamWriter.Send once the caller stopped
+// reading.
+var ErrStreamCanceled = errors.New("stream canceled")
+
+// StreamHandler produces the items of a streamed response. Returning ends the
+// stream, and a returned error reaches the caller through FunctionStream.Err
+// as a *FunctionError.
+type StreamHandler func(ctx context.Context, args map[string]interface{}, stream *StreamWriter) error
+
+// StreamListType holds the streams a module serves, by id, and the streams
+// it reads, so that closing the module stops both.
+type StreamListType struct {
+	sync.Mutex
+	m     map[string]*StreamWriter
+	pulls map[*FunctionStream]bool
+	// declare serializes declaring the stream functions, which goes over the
+	// network and so isn't done under the list's lock.
+	declare  sync.Mutex
+	declared bool
+}
+
+// StreamHandle is what CallFunction delivers for a function declared with
+// DeclareStreamFunction. CallFunctionStream reads the stream behind it.
+type StreamHandle struct {
+	Module string `json:"module"`
+	Id     string `json:"id"`
+}
+
+// streamReply is the response of a stream function, decoded by the caller
+// into a *StreamHandle.
+type streamReply struct {
+	Stream StreamHandle `json:"__stream"`
+}
+
+// StreamWriter sends items to the caller of a stream function.
+type StreamWriter struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	items    chan interface{}
+	finished chan struct{}
+	err      error
+	idle     *time.Timer
+}
+
+// Send queues value for the caller. It blocks while DefaultStreamWindow items
+// are waiting to be pulled, and fails once the stream was canceled.
+func (stream *StreamWriter) Send(value interface{}) error {
+	select {
+	case stream.items <- value:
+		return nil
+	case <-stream.ctx.Done():
+		return ErrStreamCanceled
+	}
+}
+
+// DeclareStreamFunction declares fnName as a function answering with a
+// stream. Callers read it with CallFunctionStream, while CallFunction only
+// gets a handle to the stream.
+func (module *JunoModule) DeclareStreamFunction(fnName string, handler StreamHandler) (chan interface{}, error) {
+	if err := module.declareStreamFunctions(); err != nil {
+		return nil, err
+	}
+	return module.DeclareFunctionContext(fnName, func(ctx context.Context, args map[string]interface{}) interface{} {
+		id, err := transferId()
+		if err != nil {
+			return failure(err)
+		}
+		module.startStream(detach(ctx), id, args, handler)
+		return streamReply{Stream: StreamHandle{Module: module.moduleId, Id: id}}
+	})
+}
+
+func (module *JunoModule) declareStreamFunctions() error {
+	module.streams.declare.Lock()
+	defer module.streams.declare.Unlock()
+	if module.streams.declared {
+		return nil
+	}
+	if _, err := module.DeclareFunction(streamNextFunction, module.serveStreamNext); err != nil {
+		return err
+	}
+	if _, err := module.DeclareFunction(streamCancelFunction, module.serveStreamCancel); err != nil {
+		return err
+	}
+	module.streams.declared = true
+	return nil
+}
+
+func (module *JunoModule) startStream(ctx context.Context, id string, args map[string]interface{}, handler StreamHandler) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &StreamWriter{
+		ctx:      ctx,
+		cancel:   cancel,
+		items:    make(chan interface{}, DefaultStreamWindow),
+		finished: make(chan struct{}),
+	}
+	// Streams the caller abandoned are canceled instead of blocking forever.
+	stream.idle = time.AfterFunc(streamIdleTimeout, func() {
+		module.logger.Debug("canceling idle stream", "id", id)
+		module.stopStream(id)
+	})
+	module.streams.Lock()
+	module.streams.m[id] = stream
+	module.streams.Unlock()
+
+	go func() {
+		defer close(stream.finished)
+		stream.err = handler(ctx, args, stream)
+	}()
+}
+
+// closeStreams stops the streams served and read by the module, which is
+// closing.
+func (module *JunoModule) closeStreams() {
+	module.streams.Lock()
+	ids := []string{}
+	for id := range module.streams.m {
+		ids = append(ids, id)
+	}
+	pulls := module.streams.pulls
+	module.streams.pulls = make(map[*FunctionStream]bool)
+	module.streams.Unlock()
+	for _, id := range ids {
+		module.stopStream(id)
+	}
+	for pull := range pulls {
+		pull.Cancel()
+	}
+}
+
+func (module *JunoModule) stopStream(id string) {
+	module.streams.Lock()
+	stream := module.streams.m[id]
+	delete(module.streams.m, id)
+	module.streams.Unlock()
+	if stream != nil {
+		stream.idle.Stop()
+		stream.cancel()
+	}
+}
+
+// serveStreamNext answers a pull with the items sent so far, waiting up to
+// streamPollTimeout for the first one.
+func (module *JunoModule) serveStreamNext(args map[string]interface{}) interface{} {
+	id, _ := args["id"].(string)
+	module.streams.Lock()
+	stream := module.streams.m[id]
+	module.streams.Unlock()
+	if stream == nil {
+		return failure(errors.New("stream " + id + " is no longer available"))
+	}
+	stream.idle.Reset(streamIdleTimeout)
+
+	poll := time.NewTimer(streamPollTimeout)
+	defer poll.Stop()
+	items := []interface{}{}
+	done := false
+	select {
+	case item := <-stream.items:
+		items = append(items, item)
+	case <-stream.finished:
+		done = true
+	case <-poll.C:
+	}
+drain:
+	for len(items) < cap(stream.items) {
+		select {
+		case item := <-stream.items:
+			items = append(items, item)
+		default:
+			break drain
+		}
+	}
+
+	result := map[string]interface{}{"items": items}
+	if done && len(stream.items) == 0 {
+		result["done"] = true
+		if stream.err != nil {
+			result["error"] = stream.err.Error()
+		}
+		module.stopStream(id)
+	}
+	return result
+}
+
+func (module *JunoModule) serveStreamCancel(args map[string]interface{}) interface{} {
+	id, _ := args["id"].(string)
+	module.stopStream(id)
+	return true
+}
+
+// detached keeps the values of a context, such as metadata, without its
+// cancellation, since a stream outlives the call that started it.
+type detached struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+// FunctionStream delivers the items of a streamed response.
+type FunctionStream struct {
+	items  chan interface{}
+	cancel context.CancelFunc
+	err    error
+}
+
+// Items is closed once the stream ended, failed or was canceled.
+func (stream *FunctionStream) Items() <-chan interface{} {
+	return stream.items
+}
+
+// Err reports why the stream ended early. It must be called after Items is
+// closed.
+func (stream *FunctionStream) Err() error {
+	return stream.err
+}
+
+// Cancel stops the handler and closes Items.
+func (stream *FunctionStream) Cancel() {
+	stream.cancel()
+}
+
+// CallFunctionStream calls a function declared with DeclareStreamFunction and
+// pulls its items as they are read, until the stream ends, ctx is done or the
+// module is closed. Functions answering normally deliver their response as
+// the only item.
+func (module *JunoModule) CallFunctionStream(ctx context.Context, fnName string, args map[string]interface{}, opts ...CallOption) (*FunctionStream, error) {
+	// The call isn't abandoned with ctx, so that a handle arriving late can
+	// still be used to cancel the stream.
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, WithDeadline(deadline))
+	}
+	channel, err := module.CallFunctionContext(detach(ctx), fnName, args, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream := &FunctionStream{
+		items:  make(chan interface{}),
+		cancel: cancel,
+	}
+	module.streams.Lock()
+	module.streams.pulls[stream] = true
+	module.streams.Unlock()
+	go func() {
+		defer func() {
+			module.streams.Lock()
+			delete(module.streams.pulls, stream)
+			module.streams.Unlock()
+		}()
+		defer cancel()
+		defer close(stream.items)
+		stream.err = module.pullStream(ctx, channel, stream.items)
+	}()
+	return stream, nil
+}
+
+func (module *JunoModule) pullStream(ctx context.Context, channel chan interface{}, items chan interface{}) error {
+	var response interface{}
+	select {
+	case response = <-channel:
+	case <-ctx.Done():
+		// The handler may already be running, so cancel it once its handle
+		// arrives. Past streamIdleTimeout the handler's module cancels it.
+		go func() {
+			select {
+			case response := <-channel:
+				if handle, ok := response.(*StreamHandle); ok {
+					module.cancelStream(handle.Module, handle.Id)
+				}
+			case <-time.After(streamIdleTimeout):
+			}
+		}()
+		return ctx.Err()
+	}
+	if err := responseError(response); err != nil {
+		return err
+	}
+	handle, ok := response.(*StreamHandle)
+	if !ok {
+		select {
+		case items <- response:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Unless the handler's module reported the stream done, which already
+	// removed it, it is canceled on the way out.
+	done := false
+	defer func() {
+		if !done {
+			module.cancelStream(handle.Module, handle.Id)
+		}
+	}()
+	for {
+		next, err := module.CallFunctionContext(ctx, handle.Module+"."+streamNextFunction, map[string]interface{}{"id": handle.Id})
+		if err != nil {
+			return err
+		}
+		var batch struct {
+			Items []interface{} `json:"items"`
+			Done  bool          `json:"done"`
+			Error string        `json:"error"`
+		}
+		select {
+		case response = <-next:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := responseError(response); err != nil {
+			return err
+		}
+		if err := convert(response, &batch); err != nil {
+			return err
+		}
+		done = batch.Done
+		for _, item := range batch.Items {
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if batch.Done {
+			if batch.Error != "" {
+				return &FunctionError{Message: batch.Error}
+			}
+			return nil
+		}
+	}
+}
+
+func (module *JunoModule) cancelStream(owner, id string) {
+	_, err := module.CallFunction(owner+"."+streamCancelFunction, map[string]interface{}{"id": id}, WithTimeout(replyTimeout))
+	if err != nil {
+		module.logger.Warn("failed to cancel stream", "id", id, "error", err)
+	}
+}
+
+// responseError returns the error a function response stands for, if any,
+// such as a *FunctionError.
+func responseError(response interface{}) error {
+	err, _ := response.(error)
+	return err
+}
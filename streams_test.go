@@ -0,0 +1,143 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	await(t, mustSend(t)(server.DeclareStreamFunction("count", func(ctx context.Context, args map[string]interface{}, stream *StreamWriter) error {
+		for i := 0; i < 40; i++ {
+			if err := stream.Send(i); err != nil {
+				return err
+			}
+		}
+		return errors.New("ran out")
+	})))
+
+	stream, err := client.CallFunctionStream(context.Background(), "server.count", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := []interface{}{}
+	for item := range stream.Items() {
+		received = append(received, item)
+	}
+	for i, item := range received {
+		if item != float64(i) {
+			t.Fatalf("item %d: got %v", i, item)
+		}
+	}
+	if len(received) != 40 {
+		t.Fatalf("got %d items, want 40", len(received))
+	}
+	var functionError *FunctionError
+	if !errors.As(stream.Err(), &functionError) || functionError.Message != "ran out" {
+		t.Fatalf("got error %v, want the handler's", stream.Err())
+	}
+}
+
+func TestStreamOfPlainFunction(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	want := map[string]interface{}{streamEnvelope: map[string]interface{}{"module": "server", "id": "x"}}
+	await(t, mustSend(t)(server.DeclareFunction("lookalike", func(map[string]interface{}) interface{} {
+		return want
+	})))
+
+	stream, err := client.CallFunctionStream(context.Background(), "server.lookalike", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := []interface{}{}
+	for item := range stream.Items() {
+		received = append(received, item)
+	}
+	if len(received) != 1 || !reflect.DeepEqual(received[0], want) || stream.Err() != nil {
+		t.Fatalf("got %v and %v, want the result as the only item", received, stream.Err())
+	}
+}
+
+// blockingStream declares a stream function whose handler reports on stopped
+// once its context ends.
+func blockingStream(t *testing.T, server *JunoModule) chan struct{} {
+	t.Helper()
+	stopped := make(chan struct{})
+	await(t, mustSend(t)(server.DeclareStreamFunction("wait", func(ctx context.Context, args map[string]interface{}, stream *StreamWriter) error {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	})))
+	return stopped
+}
+
+func awaitStopped(t *testing.T, stopped chan struct{}) {
+	t.Helper()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler wasn't stopped")
+	}
+}
+
+func TestStreamCanceledBeforeHandle(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	stopped := blockingStream(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream, err := client.CallFunctionStream(ctx, "server.wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range stream.Items() {
+	}
+	if !errors.Is(stream.Err(), context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", stream.Err())
+	}
+	awaitStopped(t, stopped)
+}
+
+func TestCloseStopsStreams(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	stopped := blockingStream(t, server)
+
+	stream, err := client.CallFunctionStream(context.Background(), "server.wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the stream to start, which the first pull shows.
+	for {
+		server.streams.Lock()
+		started := len(server.streams.m) > 0
+		server.streams.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Each side stops its end, since the other one can't be told anymore.
+	server.Close()
+	awaitStopped(t, stopped)
+	client.Close()
+	select {
+	case _, open := <-stream.Items():
+		if open {
+			t.Fatal("got an item from a closed module")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream wasn't stopped when its reader closed")
+	}
+}
@@ -0,0 +1,147 @@
+package juno_go
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bytesonus/juno-go/models"
+	"github.com/bytesonus/juno-go/protocol"
+	"github.com/bytesonus/juno-go/utils/error_codes"
+	"github.com/bytesonus/juno-go/utils/request_types"
+)
+
+// optionalRequestTimeout bounds how long unregistering waits for gateways
+// that silently ignore request types they don't know.
+const optionalRequestTimeout = 10 * time.Second
+
+type hookListener struct {
+	id uint64
+	// pattern is the hook or pattern the listener was registered for.
+	pattern string
+	handler HookEventHandler
+}
+
+// Subscription is returned by RegisterHook and removes the listener again.
+type Subscription struct {
+	module *JunoModule
+	hook   string
+	id     uint64
+	once   sync.Once
+	// onUnsubscribe releases whatever was built on top of the listener.
+	onUnsubscribe func()
+}
+
+func (subscription *Subscription) Hook() string {
+	return subscription.hook
+}
+
+// Id identifies the listener among the module's listeners, such as in the
+// ListenerFailure of a DeliveryReport.
+func (subscription *Subscription) Id() uint64 {
+	return subscription.id
+}
+
+// Unsubscribe stops the listener from being called. When it was the last
+// listener for its hook, the gateway is asked to stop delivering the hook.
+// Calling it more than once is harmless.
+func (subscription *Subscription) Unsubscribe() (chan interface{}, error) {
+	module := subscription.module
+	channel, err := resolved(true), error(nil)
+	subscription.once.Do(func() {
+		// Registering takes the same requests lock, which keeps register and
+		// unregister requests for a hook in order.
+		module.hookListeners.requests.Lock()
+		module.hookListeners.Lock()
+		last := module.removeListener(subscription.hook, subscription.id)
+		module.hookListeners.Unlock()
+		if last {
+			channel, err = module.sendOptionalRequest(
+				protocol.UnregisterHook(module.protocol, subscription.hook),
+			)
+		}
+		module.hookListeners.requests.Unlock()
+		// onUnsubscribe may block, such as on closing a stream, so it runs
+		// without holding any lock.
+		if subscription.onUnsubscribe != nil {
+			subscription.onUnsubscribe()
+		}
+	})
+	return channel, err
+}
+
+// removeListener reports whether the hook has no listeners left. The caller
+// holds hookListeners' lock.
+func (module *JunoModule) removeListener(hook string, id uint64) bool {
+	listeners := module.hookListeners.m[hook]
+	if len(listeners) == 0 {
+		return false
+	}
+	remaining := make([]hookListener, 0, len(listeners))
+	for _, listener := range listeners {
+		if listener.id != id {
+			remaining = append(remaining, listener)
+		}
+	}
+	if len(remaining) == len(listeners) {
+		return false
+	}
+	if len(remaining) == 0 {
+		delete(module.hookListeners.m, hook)
+		return true
+	}
+	module.hookListeners.m[hook] = remaining
+	return false
+}
+
+// UndeclareFunction stops serving fnName. Calls that still reach the module,
+// because the gateway doesn't support undeclaring, are answered with an
+// error.
+func (module *JunoModule) UndeclareFunction(fnName string) (chan interface{}, error) {
+	module.functions.Lock()
+	delete(module.functions.m, fnName)
+	delete(module.functions.schemas, fnName)
+	module.functions.Unlock()
+	return module.sendOptionalRequest(
+		protocol.UndeclareFunction(module.protocol, fnName),
+	)
+}
+
+// sendOptionalRequest sends a request juno itself doesn't implement, which
+// only gateways extending the protocol answer. Since the local state already
+// changed, the request is reported as done either way. Being refused as an
+// unknown request or getting no answer is what juno does, so only other
+// errors are logged as failures.
+func (module *JunoModule) sendOptionalRequest(message models.BaseMessage) (chan interface{}, error) {
+	channel, err := module.sendRequest(message)
+	if err != nil {
+		return nil, err
+	}
+	module.expireRequest(message.GetRequestId(), time.Now().Add(optionalRequestTimeout))
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value := <-channel
+		if err, ok := value.(error); ok {
+			var gatewayError *GatewayError
+			if errors.Is(err, context.DeadlineExceeded) ||
+				(errors.As(err, &gatewayError) && gatewayError.Code == error_codes.UnknownRequest) {
+				module.logger.Debug("gateway doesn't implement request, only changed local state",
+					"type", request_types.Name(message.GetType()))
+			} else {
+				module.logger.Warn("gateway refused request, only changed local state",
+					"type", request_types.Name(message.GetType()), "error", err)
+			}
+			value = true
+		}
+		result <- value
+	}()
+	return result, nil
+}
+
+func resolved(value interface{}) chan interface{} {
+	channel := make(chan interface{}, 1)
+	channel <- value
+	return channel
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const TraceparentKey = "traceparent"
+
+const FlagSampled byte = 0x01
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+type TraceId [16]byte
+type SpanId [8]byte
+
+type SpanContext struct {
+	TraceId TraceId
+	SpanId  SpanId
+	Flags   byte
+}
+
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceId != TraceId{} && spanContext.SpanId != SpanId{}
+}
+
+func (spanContext SpanContext) IsSampled() bool {
+	return spanContext.Flags&FlagSampled != 0
+}
+
+// Traceparent formats the span context as a W3C traceparent header value.
+func (spanContext SpanContext) Traceparent() string {
+	return fmt.Sprintf(
+		"00-%s-%s-%02x",
+		hex.EncodeToString(spanContext.TraceId[:]),
+		hex.EncodeToString(spanContext.SpanId[:]),
+		spanContext.Flags,
+	)
+}
+
+// ParseTraceparent parses a W3C traceparent header value. Versions other than
+// 00 are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	var spanContext SpanContext
+	if !decodeHex(parts[1], spanContext.TraceId[:]) ||
+		!decodeHex(parts[2], spanContext.SpanId[:]) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var flags [1]byte
+	if !decodeHex(parts[3], flags[:]) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	spanContext.Flags = flags[0]
+
+	if !spanContext.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return spanContext, nil
+}
+
+func decodeHex(value string, into []byte) bool {
+	if len(value) != hex.EncodedLen(len(into)) || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.Decode(into, []byte(value))
+	return err == nil
+}
+
+type spanContextKey struct{}
+
+func ContextWithSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, spanContext)
+}
+
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	spanContext, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return spanContext
+}
+
+// Inject writes the span context in ctx into meta, if there is one.
+func Inject(ctx context.Context, meta map[string]string) {
+	spanContext := SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		meta[TraceparentKey] = spanContext.Traceparent()
+	}
+}
+
+// Extract returns ctx carrying the remote span context found in meta.
+// Missing or malformed values leave ctx untouched.
+func Extract(ctx context.Context, meta map[string]string) context.Context {
+	spanContext, err := ParseTraceparent(meta[TraceparentKey])
+	if err != nil {
+		return ctx
+	}
+	return ContextWithSpanContext(ctx, spanContext)
+}
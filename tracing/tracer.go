@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+)
+
+type SpanKind int
+
+const (
+	SpanKindClient SpanKind = iota
+	SpanKindServer
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+type Span interface {
+	SpanContext() SpanContext
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans on behalf of a JunoModule. The parent of a new span is
+// the span context carried by ctx, which may belong to a remote module.
+// Implementations bridge to a tracing backend; the context they return must
+// carry the new span's context so that nested calls are propagated.
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// PropagatingTracer only allocates ids and propagates them, without
+// recording anything. It is the default tracer of a JunoModule.
+type PropagatingTracer struct{}
+
+func NewPropagatingTracer() *PropagatingTracer {
+	return &PropagatingTracer{}
+}
+
+func (tracer *PropagatingTracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+	span := &propagatingSpan{spanContext: SpanContext{Flags: FlagSampled}}
+	if parent.IsValid() {
+		span.spanContext.TraceId = parent.TraceId
+		span.spanContext.Flags = parent.Flags
+	} else {
+		_, _ = rand.Read(span.spanContext.TraceId[:])
+	}
+	_, _ = rand.Read(span.spanContext.SpanId[:])
+	return ContextWithSpanContext(ctx, span.spanContext), span
+}
+
+type propagatingSpan struct {
+	spanContext SpanContext
+}
+
+func (span *propagatingSpan) SpanContext() SpanContext {
+	return span.spanContext
+}
+
+func (span *propagatingSpan) SetAttribute(key string, value interface{}) {}
+
+func (span *propagatingSpan) RecordError(err error) {}
+
+func (span *propagatingSpan) End() {}
@@ -0,0 +1,213 @@
+package juno_go
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	uploadNextFunction = "__upload_next"
+	uploadEnvelope     = "__upload"
+)
+
+// DefaultUploadChunkSize is how many bytes of an upload are sent per pull.
+var DefaultUploadChunkSize = 64 * 1024
+
+// UploadHandler handles a call made with CallFunctionUpload. Reading body
+// pulls the upload from the caller while the handler runs.
+type UploadHandler func(ctx context.Context, args map[string]interface{}, body io.Reader) interface{}
+
+type UploadListType struct {
+	sync.Mutex
+	m map[string]*upload
+	// declare serializes declaring __upload_next, which goes over the
+	// network and so isn't done under the list's lock.
+	declare  sync.Mutex
+	declared bool
+}
+
+type upload struct {
+	sync.Mutex
+	body io.Reader
+	// puller is the called module, the only one allowed to pull the body.
+	puller string
+}
+
+// CallFunctionUpload calls a function declared with DeclareUploadFunction,
+// which reads body in chunks while it runs, so that large arguments don't
+// have to fit in a single message. body is read until the handler returns.
+func (module *JunoModule) CallFunctionUpload(ctx context.Context, fnName string, args map[string]interface{}, body io.Reader, opts ...CallOption) (chan interface{}, error) {
+	if err := module.declareUploadFunctions(); err != nil {
+		return nil, err
+	}
+	id, err := transferId()
+	if err != nil {
+		return nil, err
+	}
+	puller := fnName
+	if separator := strings.Index(fnName, "."); separator >= 0 {
+		puller = fnName[:separator]
+	}
+	module.uploads.Lock()
+	module.uploads.m[id] = &upload{body: body, puller: puller}
+	module.uploads.Unlock()
+
+	withUpload := map[string]interface{}{}
+	for key, value := range args {
+		withUpload[key] = value
+	}
+	withUpload[uploadEnvelope] = map[string]interface{}{
+		"module": module.moduleId,
+		"id":     id,
+	}
+	channel, err := module.CallFunctionContext(ctx, fnName, withUpload, opts...)
+	if err != nil {
+		module.removeUpload(id)
+		return nil, err
+	}
+
+	result := make(chan interface{}, 1)
+	go func() {
+		var value interface{}
+		select {
+		case value = <-channel:
+		case <-ctx.Done():
+			value = ctx.Err()
+		}
+		module.removeUpload(id)
+		result <- value
+	}()
+	return result, nil
+}
+
+func (module *JunoModule) declareUploadFunctions() error {
+	module.uploads.declare.Lock()
+	defer module.uploads.declare.Unlock()
+	if module.uploads.declared {
+		return nil
+	}
+	if _, err := module.DeclareFunction(uploadNextFunction, module.serveUploadNext); err != nil {
+		return err
+	}
+	module.uploads.declared = true
+	return nil
+}
+
+func (module *JunoModule) removeUpload(id string) {
+	module.uploads.Lock()
+	delete(module.uploads.m, id)
+	module.uploads.Unlock()
+}
+
+// serveUploadNext answers a pull with the next chunk of an upload. Only the
+// called module may pull, and other modules are told the upload doesn't
+// exist.
+func (module *JunoModule) serveUploadNext(args map[string]interface{}) interface{} {
+	id, _ := args["id"].(string)
+	puller, _ := args["module"].(string)
+	module.uploads.Lock()
+	upload := module.uploads.m[id]
+	module.uploads.Unlock()
+	if upload == nil || upload.puller != puller {
+		return failure(errors.New("upload " + id + " is no longer available"))
+	}
+
+	upload.Lock()
+	defer upload.Unlock()
+	chunk := make([]byte, DefaultUploadChunkSize)
+	read, err := io.ReadFull(upload.body, chunk)
+	switch err {
+	case nil:
+		return map[string]interface{}{"data": chunk[:read]}
+	case io.EOF, io.ErrUnexpectedEOF:
+		module.removeUpload(id)
+		return map[string]interface{}{"data": chunk[:read], "done": true}
+	default:
+		module.removeUpload(id)
+		return failure(err)
+	}
+}
+
+// DeclareUploadFunction declares fnName as a function receiving an upload.
+// Calls made without CallFunctionUpload get an empty body.
+func (module *JunoModule) DeclareUploadFunction(fnName string, handler UploadHandler) (chan interface{}, error) {
+	return module.DeclareFunctionContext(fnName, func(ctx context.Context, args map[string]interface{}) interface{} {
+		envelope, _ := args[uploadEnvelope].(map[string]interface{})
+		delete(args, uploadEnvelope)
+		if envelope == nil {
+			return handler(ctx, args, eofReader{})
+		}
+		owner, _ := envelope["module"].(string)
+		id, _ := envelope["id"].(string)
+		return handler(ctx, args, &uploadReader{
+			ctx:    ctx,
+			module: module,
+			owner:  owner,
+			id:     id,
+		})
+	})
+}
+
+// uploadReader pulls an upload from the caller's __upload_next function.
+type uploadReader struct {
+	ctx     context.Context
+	module  *JunoModule
+	owner   string
+	id      string
+	pending []byte
+	done    bool
+	err     error
+}
+
+func (reader *uploadReader) Read(buffer []byte) (int, error) {
+	for len(reader.pending) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		if reader.done {
+			return 0, io.EOF
+		}
+		reader.err = reader.pull()
+	}
+	read := copy(buffer, reader.pending)
+	reader.pending = reader.pending[read:]
+	return read, nil
+}
+
+func (reader *uploadReader) pull() error {
+	channel, err := reader.module.CallFunctionContext(reader.ctx, reader.owner+"."+uploadNextFunction, map[string]interface{}{
+		"id":     reader.id,
+		"module": reader.module.moduleId,
+	})
+	if err != nil {
+		return err
+	}
+	var response interface{}
+	select {
+	case response = <-channel:
+	case <-reader.ctx.Done():
+		return reader.ctx.Err()
+	}
+	if err := responseError(response); err != nil {
+		return err
+	}
+	chunk, _ := response.(map[string]interface{})
+	encoded, _ := chunk["data"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	reader.pending = data
+	reader.done, _ = chunk["done"].(bool)
+	return nil
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
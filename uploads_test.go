@@ -0,0 +1,80 @@
+package juno_go
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestUpload(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	await(t, mustSend(t)(server.DeclareUploadFunction("put", func(ctx context.Context, args map[string]interface{}, body io.Reader) interface{} {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return failure(err)
+		}
+		sum := sha256.Sum256(data)
+		return map[string]interface{}{"name": args["name"], "size": len(data), "sum": sum[:]}
+	})))
+
+	body := bytes.Repeat([]byte("0123456789"), DefaultUploadChunkSize/4)
+	channel, err := client.CallFunctionUpload(context.Background(), "server.put", map[string]interface{}{"name": "digits"}, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := await(t, channel).(map[string]interface{})
+	if !ok {
+		t.Fatalf("got %v", result)
+	}
+	sum := sha256.Sum256(body)
+	if result["name"] != "digits" || result["size"] != float64(len(body)) || !bytes.Equal(decodeBytes(t, result["sum"]), sum[:]) {
+		t.Fatalf("got %v", result)
+	}
+	client.uploads.Lock()
+	defer client.uploads.Unlock()
+	if len(client.uploads.m) != 0 {
+		t.Errorf("%d uploads left after the call", len(client.uploads.m))
+	}
+}
+
+func decodeBytes(t *testing.T, value interface{}) []byte {
+	t.Helper()
+	var data []byte
+	if err := convert(value, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestUploadOnlyPulledByCalledModule(t *testing.T) {
+	address := startGateway(t)
+	server := startModule(t, address, "server")
+	client := startModule(t, address, "client")
+	other := startModule(t, address, "other")
+
+	stolen := make(chan error, 1)
+	await(t, mustSend(t)(server.DeclareUploadFunction("put", func(ctx context.Context, args map[string]interface{}, body io.Reader) interface{} {
+		thief := &uploadReader{ctx: ctx, module: other, owner: "client", id: body.(*uploadReader).id}
+		stolen <- thief.pull()
+		data, _ := ioutil.ReadAll(body)
+		return string(data)
+	})))
+
+	channel, err := client.CallFunctionUpload(context.Background(), "server.put", nil, bytes.NewReader([]byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := await(t, channel); result != "secret" {
+		t.Fatalf("got %v, want the whole body", result)
+	}
+	var functionError *FunctionError
+	if err := <-stolen; !errors.As(err, &functionError) {
+		t.Fatalf("another module pulled the upload, got %v", err)
+	}
+}
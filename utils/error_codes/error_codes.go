@@ -0,0 +1,35 @@
+package error_codes
+
+const (
+	MalformedRequest   = 0
+	InvalidRequestId   = 1
+	UnknownRequest     = 2
+	UnregisteredModule = 3
+	UnknownModule      = 4
+	UnknownFunction    = 5
+	InvalidModuleId    = 6
+	DuplicateModule    = 7
+)
+
+func Name(code uint32) string {
+	switch code {
+	case MalformedRequest:
+		return "malformed request"
+	case InvalidRequestId:
+		return "invalid request id"
+	case UnknownRequest:
+		return "unknown request"
+	case UnregisteredModule:
+		return "unregistered module"
+	case UnknownModule:
+		return "unknown module"
+	case UnknownFunction:
+		return "unknown function"
+	case InvalidModuleId:
+		return "invalid module id"
+	case DuplicateModule:
+		return "duplicate module"
+	default:
+		return "unknown error"
+	}
+}
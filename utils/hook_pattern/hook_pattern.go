@@ -0,0 +1,41 @@
+// Package hook_pattern matches hook names against patterns, segment by
+// segment, segments being separated by dots. "*" matches exactly one segment
+// and "**" one or more, so "orders.*" matches "orders.created", "*.created"
+// matches "created" hooks from any module and "orders.**" matches every hook
+// of the orders module.
+package hook_pattern
+
+import "strings"
+
+func IsPattern(hook string) bool {
+	return strings.Contains(hook, "*")
+}
+
+func Match(pattern, hook string) bool {
+	if !IsPattern(pattern) {
+		return pattern == hook
+	}
+	return match(strings.Split(pattern, "."), strings.Split(hook, "."))
+}
+
+func match(pattern, hook []string) bool {
+	if len(pattern) == 0 {
+		return len(hook) == 0
+	}
+	if len(hook) == 0 {
+		return false
+	}
+	switch pattern[0] {
+	case "**":
+		for consumed := 1; consumed <= len(hook); consumed++ {
+			if match(pattern[1:], hook[consumed:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return match(pattern[1:], hook[1:])
+	default:
+		return pattern[0] == hook[0] && match(pattern[1:], hook[1:])
+	}
+}
@@ -0,0 +1,37 @@
+package hook_pattern
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		hook    string
+		matches bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.deleted", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.items.added", false},
+		{"orders.*", "users.created", false},
+		{"*.created", "orders.created", true},
+		{"*.created", "orders.items.created", false},
+		{"orders.**", "orders.created", true},
+		{"orders.**", "orders.items.added", true},
+		{"orders.**", "orders", false},
+		{"**.created", "orders.items.created", true},
+		{"**.created", "created", false},
+		{"orders.**.added", "orders.items.added", true},
+		{"orders.**.added", "orders.added", false},
+		{"*", "orders", true},
+		{"*", "orders.created", false},
+		{"**", "orders.created", true},
+		{"orders.cre*", "orders.created", false},
+	}
+
+	for _, test := range tests {
+		if got := Match(test.pattern, test.hook); got != test.matches {
+			t.Errorf("Match(%q, %q) = %v, want %v", test.pattern, test.hook, got, test.matches)
+		}
+	}
+}
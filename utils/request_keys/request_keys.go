@@ -11,5 +11,6 @@ const (
 	Hook         string = "hook"
 	Arguments    string = "arguments"
 	Data         string = "data"
+	Meta         string = "meta"
+	Deadline     string = "deadline"
 )
-
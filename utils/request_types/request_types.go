@@ -1,15 +1,56 @@
 package request_types
 
 const (
-	Error                   = 0
-	RegisterModuleRequest   = 1
-	RegisterModuleResponse  = 2
-	FunctionCallRequest     = 3
-	FunctionCallResponse    = 4
-	RegisterHookRequest     = 5
-	RegisterHookResponse    = 6
-	TriggerHookRequest      = 7
-	TriggerHookResponse     = 8
-	DeclareFunctionRequest  = 9
-	DeclareFunctionResponse = 10
-)
\ No newline at end of file
+	Error                     = 0
+	RegisterModuleRequest     = 1
+	RegisterModuleResponse    = 2
+	FunctionCallRequest       = 3
+	FunctionCallResponse      = 4
+	RegisterHookRequest       = 5
+	RegisterHookResponse      = 6
+	TriggerHookRequest        = 7
+	TriggerHookResponse       = 8
+	DeclareFunctionRequest    = 9
+	DeclareFunctionResponse   = 10
+	UnregisterHookRequest     = 11
+	UnregisterHookResponse    = 12
+	UndeclareFunctionRequest  = 13
+	UndeclareFunctionResponse = 14
+)
+
+func Name(requestType uint64) string {
+	switch requestType {
+	case Error:
+		return "Error"
+	case RegisterModuleRequest:
+		return "RegisterModuleRequest"
+	case RegisterModuleResponse:
+		return "RegisterModuleResponse"
+	case FunctionCallRequest:
+		return "FunctionCallRequest"
+	case FunctionCallResponse:
+		return "FunctionCallResponse"
+	case RegisterHookRequest:
+		return "RegisterHookRequest"
+	case RegisterHookResponse:
+		return "RegisterHookResponse"
+	case TriggerHookRequest:
+		return "TriggerHookRequest"
+	case TriggerHookResponse:
+		return "TriggerHookResponse"
+	case DeclareFunctionRequest:
+		return "DeclareFunctionRequest"
+	case DeclareFunctionResponse:
+		return "DeclareFunctionResponse"
+	case UnregisterHookRequest:
+		return "UnregisterHookRequest"
+	case UnregisterHookResponse:
+		return "UnregisterHookResponse"
+	case UndeclareFunctionRequest:
+		return "UndeclareFunctionRequest"
+	case UndeclareFunctionResponse:
+		return "UndeclareFunctionResponse"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,187 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidRange = errors.New("invalid version range")
+
+// Range is a set of version requirements, written the way juno and Cargo
+// write them: comma separated comparators that must all hold, such as
+// ">=1.2.0, <2.0.0". A bare version means the same as a caret requirement.
+// Like juno, alternatives joined with "||" aren't supported.
+type Range struct {
+	raw         string
+	comparators []comparator
+}
+
+type operator int
+
+const (
+	equal operator = iota
+	greater
+	greaterOrEqual
+	less
+	lessOrEqual
+)
+
+type comparator struct {
+	operator operator
+	version  Version
+	// origin is the requirement this bound was derived from, which decides
+	// whether pre-releases may match.
+	origin Version
+}
+
+var operatorSpacing = regexp.MustCompile(`(\^|~|>=|<=|>|<|=)\s+`)
+
+func ParseRange(value string) (Range, error) {
+	versionRange := Range{raw: strings.TrimSpace(value), comparators: []comparator{}}
+	if strings.Contains(versionRange.raw, "||") {
+		return Range{}, fmt.Errorf("%w %q: alternatives aren't supported", ErrInvalidRange, value)
+	}
+	normalized := operatorSpacing.ReplaceAllString(versionRange.raw, "$1")
+	requirements := strings.FieldsFunc(normalized, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(requirements) == 0 {
+		return Range{}, fmt.Errorf("%w %q: empty requirement", ErrInvalidRange, value)
+	}
+	for _, requirement := range requirements {
+		parsed, err := parseRequirement(requirement)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w %q: %v", ErrInvalidRange, value, err)
+		}
+		versionRange.comparators = append(versionRange.comparators, parsed...)
+	}
+	return versionRange, nil
+}
+
+func MustParseRange(value string) Range {
+	versionRange, err := ParseRange(value)
+	if err != nil {
+		panic(err)
+	}
+	return versionRange
+}
+
+func (versionRange Range) String() string {
+	return versionRange.raw
+}
+
+// Matches reports whether version satisfies the range. Pre-release versions
+// only match requirements that name a pre-release of the same version.
+func (versionRange Range) Matches(version Version) bool {
+	allowPrerelease := len(version.Prerelease) == 0
+	for _, bound := range versionRange.comparators {
+		if !bound.matches(version) {
+			return false
+		}
+		if len(bound.origin.Prerelease) > 0 && bound.origin.sameRelease(version) {
+			allowPrerelease = true
+		}
+	}
+	return allowPrerelease
+}
+
+func (bound comparator) matches(version Version) bool {
+	result := version.Compare(bound.version)
+	switch bound.operator {
+	case equal:
+		return result == 0
+	case greater:
+		return result > 0
+	case greaterOrEqual:
+		return result >= 0
+	case less:
+		return result < 0
+	default:
+		return result <= 0
+	}
+}
+
+// parseRequirement turns one requirement into the bounds it stands for.
+func parseRequirement(requirement string) ([]comparator, error) {
+	if requirement == "*" || requirement == "x" || requirement == "X" {
+		return []comparator{}, nil
+	}
+
+	prefix := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(requirement, candidate) {
+			prefix = candidate
+			break
+		}
+	}
+	version, parts, err := parsePartial(strings.TrimPrefix(requirement, prefix))
+	if err != nil {
+		return nil, err
+	}
+	if parts == 0 {
+		if prefix == "<" || prefix == ">" {
+			// Nothing is below or above every version.
+			return []comparator{{operator: less, version: Version{}}}, nil
+		}
+		return []comparator{}, nil
+	}
+
+	at := func(op operator, target Version) comparator {
+		return comparator{operator: op, version: target, origin: version}
+	}
+	floor := Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch, Prerelease: version.Prerelease}
+	// next is the first version past the last given number.
+	next := func(position int) Version {
+		switch position {
+		case 1:
+			return Version{Major: version.Major + 1}
+		case 2:
+			return Version{Major: version.Major, Minor: version.Minor + 1}
+		default:
+			return Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch + 1}
+		}
+	}
+
+	switch prefix {
+	case ">=":
+		return []comparator{at(greaterOrEqual, floor)}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{at(greater, floor)}, nil
+		}
+		return []comparator{at(greaterOrEqual, next(parts))}, nil
+	case "<":
+		return []comparator{at(less, floor)}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{at(lessOrEqual, floor)}, nil
+		}
+		return []comparator{at(less, next(parts))}, nil
+	case "=":
+		if parts == 3 {
+			return []comparator{at(equal, floor)}, nil
+		}
+		return []comparator{at(greaterOrEqual, floor), at(less, next(parts))}, nil
+	case "~":
+		position := 2
+		if parts == 1 {
+			position = 1
+		}
+		return []comparator{at(greaterOrEqual, floor), at(less, next(position))}, nil
+	default:
+		// Caret, which is also what a bare version means: changes that don't
+		// touch the left-most non-zero number are compatible.
+		position := 1
+		switch {
+		case version.Major > 0 || parts == 1:
+			position = 1
+		case version.Minor > 0 || parts == 2:
+			position = 2
+		default:
+			position = 3
+		}
+		return []comparator{at(greaterOrEqual, floor), at(less, next(position))}, nil
+	}
+}
@@ -0,0 +1,65 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseRejectsWhatJunoRejects(t *testing.T) {
+	for _, value := range []string{"v1.0.0", "1.0", "01.0.0", "1.0.0-", "1.0.0+"} {
+		if _, err := Parse(value); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("Parse(%q): got %v, want ErrInvalidVersion", value, err)
+		}
+	}
+	for _, value := range []string{"^1 || ^2", "v1.0.0", ">=v1.2", "", "1.0.0 |"} {
+		if _, err := ParseRange(value); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("ParseRange(%q): got %v, want ErrInvalidRange", value, err)
+		}
+	}
+}
+
+func TestRangeMatches(t *testing.T) {
+	tests := []struct {
+		requirement string
+		version     string
+		matches     bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.9.0", true},
+		{"1.2.3", "2.0.0", false},
+		{"1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"=1.2", "1.2.7", true},
+		{"=1.2", "1.3.0", false},
+		{">=1.2.0, <2.0.0", "1.5.0", true},
+		{">=1.2.0, <2.0.0", "2.0.0", false},
+		{">= 1.2.0, < 2.0.0", "1.2.0", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"1.x", "1.4.0", true},
+		{"1.x", "2.0.0", false},
+		{"*", "3.1.4", true},
+		{"*", "1.0.0-alpha", false},
+		{"^1.2.3", "1.3.0-alpha", false},
+		{">=1.2.3-alpha", "1.2.3-beta", true},
+		{">=1.2.3-alpha", "1.2.4-beta", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.requirement+" "+test.version, func(t *testing.T) {
+			versionRange, err := ParseRange(test.requirement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := versionRange.Matches(MustParse(test.version)); got != test.matches {
+				t.Errorf("got %v, want %v", got, test.matches)
+			}
+		})
+	}
+}
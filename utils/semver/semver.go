@@ -0,0 +1,199 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidVersion = errors.New("invalid semantic version")
+
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// Parse parses a full MAJOR.MINOR.PATCH version with optional pre-release and
+// build metadata. Like juno, it rejects a leading "v".
+func Parse(value string) (Version, error) {
+	version, parts, err := parsePartial(value)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, fmt.Errorf("%w %q: expected MAJOR.MINOR.PATCH", ErrInvalidVersion, value)
+	}
+	return version, nil
+}
+
+func MustParse(value string) Version {
+	version, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return version
+}
+
+// parsePartial parses a version that may omit its minor and patch numbers or
+// use "*" or "x" in their place, returning how many numbers were given.
+func parsePartial(value string) (Version, int, error) {
+	invalid := func(reason string) (Version, int, error) {
+		return Version{}, 0, fmt.Errorf("%w %q: %s", ErrInvalidVersion, value, reason)
+	}
+
+	text := strings.TrimSpace(value)
+	version := Version{}
+	if index := strings.Index(text, "+"); index >= 0 {
+		version.Build = strings.Split(text[index+1:], ".")
+		text = text[:index]
+		if !validIdentifiers(version.Build, false) {
+			return invalid("malformed build metadata")
+		}
+	}
+	if index := strings.Index(text, "-"); index >= 0 {
+		version.Prerelease = strings.Split(text[index+1:], ".")
+		text = text[:index]
+		if !validIdentifiers(version.Prerelease, true) {
+			return invalid("malformed pre-release")
+		}
+	}
+
+	numbers := strings.Split(text, ".")
+	if len(numbers) > 3 {
+		return invalid("too many components")
+	}
+	parts := 0
+	for i, number := range numbers {
+		if number == "*" || number == "x" || number == "X" {
+			break
+		}
+		if number == "" || (len(number) > 1 && number[0] == '0') {
+			return invalid("malformed number")
+		}
+		parsed, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return invalid("malformed number")
+		}
+		switch i {
+		case 0:
+			version.Major = parsed
+		case 1:
+			version.Minor = parsed
+		case 2:
+			version.Patch = parsed
+		}
+		parts++
+	}
+	if parts < len(numbers) {
+		for _, rest := range numbers[parts:] {
+			if rest != "*" && rest != "x" && rest != "X" {
+				return invalid("numbers can't follow a wildcard")
+			}
+		}
+	}
+	if parts < 3 && (version.Prerelease != nil || version.Build != nil) {
+		return invalid("pre-release requires a full version")
+	}
+	return version, parts, nil
+}
+
+func validIdentifiers(identifiers []string, numeric bool) bool {
+	for _, identifier := range identifiers {
+		if identifier == "" {
+			return false
+		}
+		for _, r := range identifier {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return false
+			}
+		}
+		if numeric && isNumeric(identifier) && len(identifier) > 1 && identifier[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(identifier string) bool {
+	for _, r := range identifier {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (version Version) String() string {
+	text := fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch)
+	if len(version.Prerelease) > 0 {
+		text += "-" + strings.Join(version.Prerelease, ".")
+	}
+	if len(version.Build) > 0 {
+		text += "+" + strings.Join(version.Build, ".")
+	}
+	return text
+}
+
+// Compare returns -1, 0 or 1 depending on whether version has lower, equal or
+// higher precedence than other. Build metadata is ignored.
+func (version Version) Compare(other Version) int {
+	if result := compareNumbers(version.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareNumbers(version.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareNumbers(version.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	switch {
+	case len(version.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(version.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(version.Prerelease) && i < len(other.Prerelease); i++ {
+		if result := compareIdentifiers(version.Prerelease[i], other.Prerelease[i]); result != 0 {
+			return result
+		}
+	}
+	return compareNumbers(uint64(len(version.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+func (version Version) sameRelease(other Version) bool {
+	return version.Major == other.Major && version.Minor == other.Minor && version.Patch == other.Patch
+}
+
+func compareNumbers(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareIdentifiers(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case aNumeric && bNumeric:
+		aNumber, _ := strconv.ParseUint(a, 10, 64)
+		bNumber, _ := strconv.ParseUint(b, 10, 64)
+		return compareNumbers(aNumber, bNumber)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}